@@ -0,0 +1,186 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	mqttout "github.com/ericogr/ads1115-to-mqtt/pkg/output/mqtt"
+	sqliteout "github.com/ericogr/ads1115-to-mqtt/pkg/output/sqlite"
+)
+
+// subcommands maps a subcommand name (first CLI argument) to its entry point.
+// Subcommands share the regular flags and configuration loading.
+var subcommands = map[string]func(){
+	"discovery-cleanup": runDiscoveryCleanup,
+	"history":           runHistory,
+}
+
+// runSubcommand runs the subcommand named by the first CLI argument, if any,
+// and reports whether one was run.
+func runSubcommand() bool {
+	if len(os.Args) < 2 {
+		return false
+	}
+	cmd, ok := subcommands[os.Args[1]]
+	if !ok {
+		return false
+	}
+	// drop the subcommand so the remaining flags are parsed as usual
+	os.Args = append(os.Args[:1], os.Args[2:]...)
+	cmd()
+	return true
+}
+
+// runDiscoveryCleanup clears stale Home Assistant discovery entities for every
+// configured mqtt output and exits.
+func runDiscoveryCleanup() {
+	all := flag.Bool("all", false, "discovery-cleanup: clear every known discovery entity, not only stale ones")
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	targets, err := discoveryTargets(cfg)
+	if err != nil {
+		log.Fatalf("discovery cleanup: %v", err)
+	}
+	if len(targets) == 0 {
+		log.Fatalf("discovery cleanup: no mqtt outputs configured")
+	}
+	for _, t := range targets {
+		cleared, err := mqttout.CleanupDiscovery(t.cfg, t.channels, *all)
+		if err != nil {
+			log.Fatalf("discovery cleanup (%s): %v", t.cfg.Server, err)
+		}
+		for _, topic := range cleared {
+			fmt.Printf("cleared %s\n", topic)
+		}
+		log.Printf("discovery cleanup (%s): %d entities cleared", t.cfg.Server, len(cleared))
+	}
+}
+
+// discoveryTarget is an mqtt output whose discovery entities are cleaned up.
+type discoveryTarget struct {
+	cfg      mqttout.Config
+	channels []config.ChannelConfig
+}
+
+// discoveryTargets returns the settings and channels of the mqtt outputs,
+// whether configured with a typed section or options.
+func discoveryTargets(cfg config.Config) ([]discoveryTarget, error) {
+	var targets []discoveryTarget
+	for i, o := range cfg.Outputs {
+		if strings.ToLower(o.Type) != "mqtt" {
+			continue
+		}
+		opts, err := output.DecodeOptions(o)
+		if err != nil {
+			return nil, fmt.Errorf("outputs[%d]: %w", i, err)
+		}
+		targets = append(targets, discoveryTarget{cfg: *opts.(*mqttout.Config), channels: o.SelectChannels(cfg.Channels)})
+	}
+	return targets, nil
+}
+
+// runHistory prints the stored history of a channel from a sqlite output
+// database as a table or CSV and exits.
+func runHistory() {
+	db := flag.String("db", "", "history: sqlite database path (default: the path of the first sqlite output)")
+	channel := flag.Int("channel", 0, "history: channel to query")
+	from := flag.String("from", "24h", "history: start of the range, RFC3339 or a duration before now")
+	to := flag.String("to", "", "history: end of the range, RFC3339 or a duration before now (default: now)")
+	format := flag.String("format", "table", "history: output format (table|csv)")
+	out := flag.String("out", "", "history: write to this file instead of stdout")
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	path := *db
+	if path == "" {
+		if path, err = historyPath(cfg); err != nil {
+			log.Fatalf("history: %v", err)
+		}
+	}
+	if path == "" {
+		log.Fatalf("history: no sqlite output configured and no -db given")
+	}
+	if _, err := os.Stat(path); err != nil {
+		log.Fatalf("history: %v", err)
+	}
+	now := time.Now()
+	start, err := parseHistoryTime(*from, now)
+	if err != nil {
+		log.Fatalf("history: -from: %v", err)
+	}
+	end := now
+	if *to != "" {
+		if end, err = parseHistoryTime(*to, now); err != nil {
+			log.Fatalf("history: -to: %v", err)
+		}
+	}
+	var write func(io.Writer, []sqliteout.Row) error
+	switch strings.ToLower(*format) {
+	case "table":
+		write = sqliteout.WriteTable
+	case "csv":
+		write = sqliteout.WriteCSV
+	default:
+		log.Fatalf("history: unknown format %q (want table or csv)", *format)
+	}
+	h, err := sqliteout.Open(path)
+	if err != nil {
+		log.Fatalf("history: %v", err)
+	}
+	defer h.Close()
+	rows, err := sqliteout.Query(h, *channel, start, end)
+	if err != nil {
+		log.Fatalf("history: %v", err)
+	}
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("history: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := write(w, rows); err != nil {
+		log.Fatalf("history: %v", err)
+	}
+	log.Printf("history: %d rows for channel %d", len(rows), *channel)
+}
+
+// historyPath returns the database path of the first sqlite output, or ""
+// when there is none.
+func historyPath(cfg config.Config) (string, error) {
+	for i, o := range cfg.Outputs {
+		if strings.ToLower(o.Type) != "sqlite" {
+			continue
+		}
+		opts, err := output.DecodeOptions(o)
+		if err != nil {
+			return "", fmt.Errorf("outputs[%d]: %w", i, err)
+		}
+		return opts.(*sqliteout.Config).Path, nil
+	}
+	return "", nil
+}
+
+// parseHistoryTime parses an RFC3339 time or a duration before now.
+func parseHistoryTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid time %q (want RFC3339 or a duration such as 6h)", s)
+	}
+	return now.Add(-d), nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/control"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/latest"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+// maxChannel is the highest ADS1115 single-ended channel index.
+const maxChannel = 3
+
+// controller applies remote control commands to the running configuration,
+// sensor and output workers, and persists accepted changes to the config file.
+type controller struct {
+	mu          sync.Mutex
+	cfg         *config.Config
+	sensor      sensor.Sensor
+	outs        []outputEntry
+	store       *latest.Store
+	sensorReset chan time.Duration
+}
+
+func newController(cfg *config.Config, s sensor.Sensor, outs []outputEntry, store *latest.Store, sensorReset chan time.Duration) *controller {
+	return &controller{cfg: cfg, sensor: s, outs: outs, store: store, sensorReset: sensorReset}
+}
+
+// Handle implements control.Handler.
+func (c *controller) Handle(cmd control.Command) control.Response {
+	switch cmd.Command {
+	case control.CmdGetConfig:
+		return control.OK(cmd, c.redactedConfig())
+	case control.CmdReadNow:
+		readings, err := readAndUpdate(c.sensor, c.outs, c.store)
+		if err != nil {
+			return control.Errorf(cmd, "read: %v", err)
+		}
+		return control.OK(cmd, readings)
+	case control.CmdSetChannel:
+		if cmd.Channel == nil || (cmd.Enabled == nil && cmd.SampleRate == nil) {
+			return control.Errorf(cmd, "%s requires channel and enabled or sample_rate", cmd.Command)
+		}
+		if cmd.SampleRate != nil && *cmd.SampleRate != 0 {
+			if err := config.ValidateSampleRate(*cmd.SampleRate); err != nil {
+				return control.Errorf(cmd, "%v", err)
+			}
+		}
+		return c.update(cmd, func(cfg *config.Config) (interface{}, error) {
+			ch, err := channelConfig(cfg, *cmd.Channel)
+			if err != nil {
+				return nil, err
+			}
+			if cmd.Enabled != nil {
+				ch.Enabled = *cmd.Enabled
+			}
+			if cmd.SampleRate != nil {
+				ch.SampleRate = *cmd.SampleRate
+			}
+			return *ch, nil
+		})
+	case control.CmdSetCalibration:
+		if cmd.Channel == nil || (cmd.CalibrationScale == nil && cmd.CalibrationOffset == nil) {
+			return control.Errorf(cmd, "%s requires channel and calibration_scale or calibration_offset", cmd.Command)
+		}
+		if err := cmd.ValidateCalibration(); err != nil {
+			return control.Errorf(cmd, "%v", err)
+		}
+		return c.update(cmd, func(cfg *config.Config) (interface{}, error) {
+			ch, err := channelConfig(cfg, *cmd.Channel)
+			if err != nil {
+				return nil, err
+			}
+			if cmd.CalibrationScale != nil {
+				ch.CalibrationScale = *cmd.CalibrationScale
+			}
+			if cmd.CalibrationOffset != nil {
+				ch.CalibrationOffset = *cmd.CalibrationOffset
+			}
+			return *ch, nil
+		})
+	case control.CmdSetInterval:
+		if cmd.Output == nil || cmd.IntervalMs <= 0 {
+			return control.Errorf(cmd, "%s requires output and a positive interval_ms", cmd.Command)
+		}
+		return c.update(cmd, func(cfg *config.Config) (interface{}, error) {
+			if *cmd.Output < 0 || *cmd.Output >= len(cfg.Outputs) {
+				return nil, fmt.Errorf("invalid output %d", *cmd.Output)
+			}
+			o := &cfg.Outputs[*cmd.Output]
+			o.IntervalMs = cmd.IntervalMs
+			return *o, nil
+		})
+	default:
+		return control.Errorf(cmd, "unknown command %q", cmd.Command)
+	}
+}
+
+// redactedConfig returns a copy of the running configuration without secrets.
+func (c *controller) redactedConfig() config.Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cfg.Redacted()
+}
+
+// update applies mutate to the running configuration, propagates the change to
+// the sensor and workers and persists it to the config file (if any).
+func (c *controller) update(cmd control.Command, mutate func(*config.Config) (interface{}, error)) control.Response {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, err := mutate(c.cfg)
+	if err != nil {
+		return control.Errorf(cmd, "%v", err)
+	}
+	c.apply()
+	log.Printf("command %s applied", cmd.Command)
+	if c.cfg.Path == "" {
+		return control.OK(cmd, result)
+	}
+	// outputs are addressed by index, which only matches the file when the
+	// outputs were read from it
+	if cmd.Output != nil && c.cfg.OutputsFromFlags {
+		return control.Errorf(cmd, "applied but not persisted: the outputs were set by command-line flags")
+	}
+	if err := persistConfig(c.cfg.Path, mutate); err != nil {
+		return control.Errorf(cmd, "applied but not persisted: %v", err)
+	}
+	return control.OK(cmd, result)
+}
+
+// apply pushes the running configuration to the sensor, the outputs
+// announcing their channels and the worker tickers.
+func (c *controller) apply() {
+	if cs, ok := c.sensor.(sensor.Configurable); ok {
+		cs.UpdateChannels(c.cfg.Channels)
+	}
+	sendInterval(c.sensorReset, time.Duration(computeSensorInterval(*c.cfg))*time.Millisecond)
+	for i := range c.outs {
+		e := &c.outs[i]
+		if e.Index >= len(c.cfg.Outputs) {
+			continue
+		}
+		if u, ok := e.Out.(output.ChannelUpdater); ok {
+			if err := u.UpdateChannels(c.cfg.Outputs[e.Index].SelectChannels(c.cfg.Channels)); err != nil {
+				log.Printf("warning: output %d: update channels: %v", e.Index, err)
+			}
+		}
+		if iv := c.cfg.Outputs[e.Index].IntervalMs; iv > 0 && iv != e.IntervalMs {
+			e.IntervalMs = iv
+			sendInterval(e.reset, time.Duration(iv)*time.Millisecond)
+		}
+	}
+}
+
+// persistConfig applies mutate to the configuration file so only the changed
+// fields are written (flag overrides are not baked into the file).
+func persistConfig(path string, mutate func(*config.Config) (interface{}, error)) error {
+	fileCfg, err := config.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := mutate(&fileCfg); err != nil {
+		return err
+	}
+	return config.Save(path, fileCfg)
+}
+
+// channelConfig returns the configuration of channel ch, adding it with
+// default calibration if missing.
+func channelConfig(cfg *config.Config, ch int) (*config.ChannelConfig, error) {
+	if ch < 0 || ch > maxChannel {
+		return nil, fmt.Errorf("invalid channel %d", ch)
+	}
+	for i := range cfg.Channels {
+		if cfg.Channels[i].Channel == ch {
+			return &cfg.Channels[i], nil
+		}
+	}
+	cfg.Channels = append(cfg.Channels, config.ChannelConfig{Channel: ch, CalibrationScale: 1.0})
+	return &cfg.Channels[len(cfg.Channels)-1], nil
+}
+
+// sendInterval delivers d on a buffered reset channel, replacing any pending value.
+func sendInterval(ch chan time.Duration, d time.Duration) {
+	if ch == nil || d <= 0 {
+		return
+	}
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- d
+}
@@ -12,14 +12,22 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ericogr/ads1115-to-mqtt/pkg/api"
 	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/control"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/grpcapi"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/latest"
 	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
-	console "github.com/ericogr/ads1115-to-mqtt/pkg/output/console"
-	mqttout "github.com/ericogr/ads1115-to-mqtt/pkg/output/mqtt"
 	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/stats"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/stream"
 )
 
 func main() {
+	if runSubcommand() {
+		return
+	}
+
 	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatalf("config: %v", err)
@@ -77,8 +85,13 @@ func computeSensorInterval(cfg config.Config) int {
 }
 
 // loadConfig loads configuration from flags or a JSON file.
+// Output types are checked against the output registry.
 func loadConfig() (config.Config, error) {
-	return config.LoadFromFlags()
+	cfg, err := config.LoadFromFlags()
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, output.Validate(cfg.Outputs)
 }
 
 // initOutputs constructs the configured outputs (console, mqtt, ...).
@@ -96,37 +109,37 @@ type channelAgg struct {
 type outputEntry struct {
 	Out        output.Output
 	IntervalMs int
-	mu         sync.Mutex
-	aggs       map[int]*channelAgg
+	// Index is the position of this output in cfg.Outputs.
+	Index int
+	mu    sync.Mutex
+	aggs  map[int]*channelAgg
+	// channels is the channel selection of the output; nil means every channel.
+	channels map[int]bool
+	// reset delivers a new publish interval to the output worker.
+	reset chan time.Duration
+
+	// typ, policy and counters drive the publish error handling; pending
+	// and lastFailureLog are only used by the output worker.
+	typ            string
+	policy         errorPolicy
+	counters       *stats.OutputCounters
+	pending        [][]sensor.Reading
+	lastFailureLog time.Time
 }
 
 func initOutputs(cfg *config.Config, sensorIntervalMs int) ([]outputEntry, error) {
 	entries := make([]outputEntry, 0, len(cfg.Outputs))
 	for i := range cfg.Outputs {
 		o := &cfg.Outputs[i]
-		typ := strings.ToLower(o.Type)
 		if o.IntervalMs == 0 {
 			o.IntervalMs = sensorIntervalMs
 		}
-		interval := o.IntervalMs
-		switch typ {
-		case "console":
-			entries = append(entries, makeOutputEntry(console.NewConsole(), interval))
-		case "mqtt":
-			var mqttCfg config.MQTTConfig
-			if o.MQTT != nil {
-				mqttCfg = *o.MQTT
-			} else {
-				mqttCfg = config.MQTTConfig{Server: mqttout.DefaultServer, ClientID: mqttout.DefaultClientID, StateTopic: mqttout.DefaultStateTopic}
-			}
-			mo, err := mqttout.NewMQTT(mqttCfg, cfg.Channels)
-			if err != nil {
-				return nil, fmt.Errorf("mqtt init: %w", err)
-			}
-			entries = append(entries, makeOutputEntry(mo, interval))
-		default:
-			log.Printf("warning: unknown output '%s', ignoring", o.Type)
+		env := output.Env{Config: cfg, Channels: o.SelectChannels(cfg.Channels), Version: Version}
+		out, err := output.New(*o, env)
+		if err != nil {
+			return nil, err
 		}
+		entries = append(entries, makeOutputEntry(out, i, *o))
 	}
 	if len(entries) == 0 {
 		return nil, fmt.Errorf("no outputs configured")
@@ -134,9 +147,29 @@ func initOutputs(cfg *config.Config, sensorIntervalMs int) ([]outputEntry, error
 	return entries, nil
 }
 
-// makeOutputEntry creates a new outputEntry with initialized aggregators.
-func makeOutputEntry(o output.Output, interval int) outputEntry {
-	return outputEntry{Out: o, IntervalMs: interval, aggs: make(map[int]*channelAgg)}
+// makeOutputEntry creates a new outputEntry with initialized aggregators for
+// the output configured at cfg.Outputs[index].
+func makeOutputEntry(o output.Output, index int, oc config.OutputConfig) outputEntry {
+	var selected map[int]bool
+	if len(oc.Channels) > 0 {
+		selected = make(map[int]bool, len(oc.Channels))
+		for _, ch := range oc.Channels {
+			selected[ch] = true
+		}
+	}
+	typ := strings.ToLower(oc.Type)
+	policy := newErrorPolicy(oc.OnError)
+	return outputEntry{
+		Out:        o,
+		IntervalMs: oc.IntervalMs,
+		Index:      index,
+		aggs:       make(map[int]*channelAgg),
+		channels:   selected,
+		reset:      make(chan time.Duration, 1),
+		typ:        typ,
+		policy:     policy,
+		counters:   stats.Default.Output(index, typ, policy.policy),
+	}
 }
 
 // initSensor creates a sensor implementation (real ADS1115 or fake simulator).
@@ -157,8 +190,10 @@ func runLoop(cfg config.Config, s sensor.Sensor, outs []outputEntry, sensorInter
 	// no global latest snapshot needed; each output aggregates values independently
 
 	done := make(chan struct{})
+	sensorReset := make(chan time.Duration, 1)
+	store := latest.New()
 	// start sensor reader and output workers
-	startSensorReader(s, outs, sensorIntervalMs, done)
+	startSensorReader(s, outs, store, sensorIntervalMs, sensorReset, done)
 	startOutputWorkers(outs, done)
 
 	log.Printf("started; version=%s commit=%s built=%s; sensor_type=%s sample_rate=%d sensor_interval=%dms outputs=%v", Version, Commit, BuildDate, cfg.SensorType, cfg.SampleRate, sensorIntervalMs, cfg.Outputs)
@@ -168,31 +203,64 @@ func runLoop(cfg config.Config, s sensor.Sensor, outs []outputEntry, sensorInter
 		fmt.Printf("config:\n%s\n", string(b))
 	}
 
+	// accept remote control commands on outputs that support them
+	ctl := newController(&cfg, s, outs, store, sensorReset)
+	for i := range outs {
+		if c, ok := outs[i].Out.(control.Commandable); ok {
+			if err := c.SetCommandHandler(ctl); err != nil {
+				log.Printf("warning: command handler: %v", err)
+			}
+		}
+	}
+
+	var apiServer *api.Server
+	if cfg.HTTP != nil {
+		apiServer = api.New(store, ctl.redactedConfig, api.Info{Version: Version, Commit: Commit, BuildDate: BuildDate})
+		apiServer.Handle("GET /api/stream", stream.Default)
+		apiServer.Handle("GET /stream", stream.Page())
+		if err := apiServer.ListenAndServe(*cfg.HTTP); err != nil {
+			log.Fatalf("http: %v", err)
+		}
+	}
+
+	var grpcServer *grpcapi.Server
+	if cfg.GRPC != nil {
+		grpcServer = grpcapi.New(store, ctl.redactedConfig, stream.Default)
+		if err := grpcServer.ListenAndServe(*cfg.GRPC); err != nil {
+			log.Fatalf("grpc: %v", err)
+		}
+	}
+
 	<-stop
 	close(done)
 	log.Println("shutting down")
-	for _, e := range outs {
-		_ = e.Out.Close()
+	if apiServer != nil {
+		_ = apiServer.Close()
+	}
+	if grpcServer != nil {
+		grpcServer.Close()
+	}
+	for i := range outs {
+		_ = outs[i].Out.Close()
 	}
 }
 
 // startSensorReader starts a goroutine that periodically reads from the sensor
-// and updates per-output aggregators.
-func startSensorReader(s sensor.Sensor, outs []outputEntry, sensorIntervalMs int, done <-chan struct{}) {
+// and updates per-output aggregators, the latest readings store and the raw
+// stream.
+// A new interval received on reset replaces the current one.
+func startSensorReader(s sensor.Sensor, outs []outputEntry, store *latest.Store, sensorIntervalMs int, reset <-chan time.Duration, done <-chan struct{}) {
 	ticker := time.NewTicker(time.Duration(sensorIntervalMs) * time.Millisecond)
 	go func() {
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				readings, err := s.Read()
-				if err != nil {
+				if _, err := readAndUpdate(s, outs, store); err != nil {
 					log.Printf("read error: %v", err)
-					continue
-				}
-				for i := range outs {
-					updateEntryWithReadings(&outs[i], readings)
 				}
+			case d := <-reset:
+				ticker.Reset(d)
 			case <-done:
 				return
 			}
@@ -200,11 +268,31 @@ func startSensorReader(s sensor.Sensor, outs []outputEntry, sensorIntervalMs int
 	}()
 }
 
+// readAndUpdate reads the sensor once, feeds every output aggregator, the
+// latest readings store and the raw stream, and updates the read counters.
+func readAndUpdate(s sensor.Sensor, outs []outputEntry, store *latest.Store) ([]sensor.Reading, error) {
+	readings, err := s.Read()
+	if err != nil {
+		stats.Default.RecordReadError()
+		return nil, err
+	}
+	stats.Default.RecordRead(time.Now())
+	for i := range outs {
+		updateEntryWithReadings(&outs[i], readings)
+	}
+	store.Update(readings)
+	stream.Default.Publish(stream.KindRaw, readings)
+	return readings, nil
+}
+
 // updateEntryWithReadings applies readings into the given entry's aggregators.
 func updateEntryWithReadings(entry *outputEntry, readings []sensor.Reading) {
 	entry.mu.Lock()
 	defer entry.mu.Unlock()
 	for _, r := range readings {
+		if entry.channels != nil && !entry.channels[r.Channel] {
+			continue
+		}
 		a, ok := entry.aggs[r.Channel]
 		if !ok || a == nil {
 			a = &channelAgg{}
@@ -220,7 +308,8 @@ func updateEntryWithReadings(entry *outputEntry, readings []sensor.Reading) {
 }
 
 // startOutputWorkers starts a goroutine per output that publishes aggregated
-// snapshots at the configured interval.
+// snapshots at the configured interval, handling failures with the error
+// policy of the output.
 func startOutputWorkers(outs []outputEntry, done <-chan struct{}) {
 	for i := range outs {
 		entry := &outs[i]
@@ -230,13 +319,9 @@ func startOutputWorkers(outs []outputEntry, done <-chan struct{}) {
 			for {
 				select {
 				case <-ticker.C:
-					snapshot := buildSnapshotAndReset(entry)
-					if len(snapshot) == 0 {
-						continue
-					}
-					if err := entry.Out.Publish(snapshot); err != nil {
-						log.Fatalf("output publish error: %v", err)
-					}
+					entry.publish(buildSnapshotAndReset(entry), done)
+				case d := <-entry.reset:
+					ticker.Reset(d)
 				case <-done:
 					return
 				}
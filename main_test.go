@@ -1,9 +1,18 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/control"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/latest"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
 )
 
 func TestComputeSensorInterval(t *testing.T) {
@@ -48,3 +57,201 @@ func TestInitOutputsSetsInterval(t *testing.T) {
 		t.Fatalf("entry interval not set, got %d", entries[0].IntervalMs)
 	}
 }
+
+func TestControllerCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	fileCfg := config.DefaultConfig()
+	fileCfg.SensorType = "simulation"
+	if err := config.Save(path, fileCfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	s, err := sensor.NewFakeSensor(cfg)
+	if err != nil {
+		t.Fatalf("fake sensor: %v", err)
+	}
+	outs, err := initOutputs(&cfg, 100)
+	if err != nil {
+		t.Fatalf("initOutputs: %v", err)
+	}
+	store := latest.New()
+	ctl := newController(&cfg, s, outs, store, make(chan time.Duration, 1))
+
+	ch, enabled := 1, true
+	if resp := ctl.Handle(control.Command{ID: "a", Command: control.CmdSetChannel, Channel: &ch, Enabled: &enabled}); !resp.OK || resp.ID != "a" {
+		t.Fatalf("set_channel: %+v", resp)
+	}
+	scale := 2.0
+	if resp := ctl.Handle(control.Command{Command: control.CmdSetCalibration, Channel: &ch, CalibrationScale: &scale}); !resp.OK {
+		t.Fatalf("set_calibration: %+v", resp)
+	}
+	for _, bad := range []float64{0, math.NaN(), math.Inf(1)} {
+		if resp := ctl.Handle(control.Command{Command: control.CmdSetCalibration, Channel: &ch, CalibrationScale: &bad}); resp.OK {
+			t.Fatalf("set_calibration scale %v accepted", bad)
+		}
+	}
+	out := 0
+	if resp := ctl.Handle(control.Command{Command: control.CmdSetInterval, Output: &out, IntervalMs: 2500}); !resp.OK {
+		t.Fatalf("set_interval: %+v", resp)
+	}
+	select {
+	case d := <-outs[0].reset:
+		if d != 2500*time.Millisecond {
+			t.Fatalf("output reset interval: %v", d)
+		}
+	default:
+		t.Fatalf("output interval not reset")
+	}
+
+	resp := ctl.Handle(control.Command{Command: control.CmdReadNow})
+	readings, ok := resp.Result.([]sensor.Reading)
+	if !resp.OK || !ok || len(readings) != 1 || readings[0].Channel != 1 {
+		t.Fatalf("read_now: %+v", resp)
+	}
+	// the reading reaches the HTTP and gRPC APIs
+	if got, ok := store.Get(1); !ok || !got.Timestamp.Equal(readings[0].Timestamp) {
+		t.Fatalf("latest after read_now = %+v, %v", got, ok)
+	}
+
+	bad := 7
+	if resp := ctl.Handle(control.Command{Command: control.CmdSetChannel, Channel: &bad, Enabled: &enabled}); resp.OK {
+		t.Fatalf("expected error for invalid channel")
+	}
+	if resp := ctl.Handle(control.Command{Command: "reboot"}); resp.OK {
+		t.Fatalf("expected error for unknown command")
+	}
+
+	// accepted changes are persisted to the config file
+	saved, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if !saved.Channels[1].Enabled || saved.Channels[1].CalibrationScale != 2.0 || saved.Outputs[0].IntervalMs != 2500 {
+		t.Fatalf("persisted config: %+v", saved)
+	}
+
+	// output indexes set by flags do not address the outputs of the file
+	cfg.OutputsFromFlags = true
+	resp = ctl.Handle(control.Command{Command: control.CmdSetInterval, Output: &out, IntervalMs: 4000})
+	if resp.OK || !strings.Contains(resp.Error, "not persisted") || cfg.Outputs[0].IntervalMs != 4000 {
+		t.Fatalf("set_interval with flag outputs: %+v", resp)
+	}
+	if saved, _ := config.LoadFile(path); saved.Outputs[0].IntervalMs != 2500 {
+		t.Fatalf("flag outputs persisted: %+v", saved.Outputs)
+	}
+}
+
+func TestInitOutputsUnknownType(t *testing.T) {
+	cfg := config.Config{Outputs: []config.OutputConfig{{Type: "console"}, {Type: "bogus"}}}
+	if _, err := initOutputs(&cfg, 100); err == nil || !strings.Contains(err.Error(), `unknown output type "bogus"`) {
+		t.Fatalf("initOutputs error = %v", err)
+	}
+}
+
+func TestSubcommandOutputOptions(t *testing.T) {
+	cfg := config.Config{
+		Channels: []config.ChannelConfig{{Channel: 0, Name: "battery"}, {Channel: 1}},
+		Outputs: []config.OutputConfig{
+			{Type: "console"},
+			{Type: "mqtt", Channels: []int{1}, Options: json.RawMessage(`{"server":"tcp://broker:1883","discovery_topic":"homeassistant/sensor/ch%d/config"}`)},
+			{Type: "sqlite", Options: json.RawMessage(`{"path":"/var/lib/ads1115/history.db"}`)},
+		},
+	}
+	targets, err := discoveryTargets(cfg)
+	if err != nil {
+		t.Fatalf("discoveryTargets: %v", err)
+	}
+	if len(targets) != 1 || targets[0].cfg.Server != "tcp://broker:1883" || targets[0].cfg.StateTopic == "" || len(targets[0].channels) != 1 {
+		t.Fatalf("targets = %+v", targets)
+	}
+	if path, err := historyPath(cfg); err != nil || path != "/var/lib/ads1115/history.db" {
+		t.Fatalf("historyPath = %q, %v", path, err)
+	}
+}
+
+func TestOutputChannelSelection(t *testing.T) {
+	cfg := config.Config{
+		Channels: []config.ChannelConfig{{Channel: 0, Name: "battery"}, {Channel: 1}},
+		Outputs:  []config.OutputConfig{{Type: "console", Channels: []int{1}}, {Type: "console"}},
+	}
+	entries, err := initOutputs(&cfg, 100)
+	if err != nil {
+		t.Fatalf("initOutputs: %v", err)
+	}
+	readings := []sensor.Reading{{Channel: 0, Value: 1}, {Channel: 1, Value: 2}}
+	for i := range entries {
+		updateEntryWithReadings(&entries[i], readings)
+	}
+	if got := buildSnapshotAndReset(&entries[0]); len(got) != 1 || got[0].Channel != 1 {
+		t.Fatalf("filtered snapshot = %+v", got)
+	}
+	if got := buildSnapshotAndReset(&entries[1]); len(got) != 2 {
+		t.Fatalf("unfiltered snapshot = %+v", got)
+	}
+}
+
+// flakyOutput fails its first fails publishes.
+type flakyOutput struct {
+	fails     int
+	published [][]sensor.Reading
+}
+
+func (f *flakyOutput) Publish(r []sensor.Reading) error {
+	if f.fails > 0 {
+		f.fails--
+		return errors.New("unavailable")
+	}
+	f.published = append(f.published, r)
+	return nil
+}
+
+func (f *flakyOutput) Close() error { return nil }
+
+func TestErrorPolicies(t *testing.T) {
+	snapshot := func(v float64) []sensor.Reading { return []sensor.Reading{{Channel: 0, Value: v}} }
+	done := make(chan struct{})
+
+	drop := &flakyOutput{fails: 1}
+	e := makeOutputEntry(drop, 90, config.OutputConfig{Type: "test"})
+	e.publish(snapshot(1), done)
+	e.publish(snapshot(2), done)
+	if s := e.counters.Snapshot(); len(drop.published) != 1 || s.Dropped != 1 || s.Errors != 1 || s.Published != 1 || s.OnError != config.OnErrorDrop {
+		t.Fatalf("drop: published %v, counters %+v", drop.published, s)
+	}
+
+	retry := &flakyOutput{fails: 2}
+	e = makeOutputEntry(retry, 91, config.OutputConfig{Type: "test", OnError: &config.ErrorPolicyConfig{Policy: config.OnErrorRetry, Attempts: 2, BackoffMs: 1}})
+	e.publish(snapshot(1), done)
+	if s := e.counters.Snapshot(); len(retry.published) != 1 || s.Retries != 2 || s.Dropped != 0 || s.ConsecutiveErrors != 0 {
+		t.Fatalf("retry: published %v, counters %+v", retry.published, s)
+	}
+
+	buffer := &flakyOutput{fails: 3}
+	e = makeOutputEntry(buffer, 92, config.OutputConfig{Type: "test", OnError: &config.ErrorPolicyConfig{Policy: config.OnErrorBuffer, Buffer: 2}})
+	for v := 1.0; v <= 3; v++ {
+		e.publish(snapshot(v), done)
+	}
+	if s := e.counters.Snapshot(); s.Buffered != 2 || s.Dropped != 1 {
+		t.Fatalf("buffer: counters %+v", s)
+	}
+	e.publish(nil, done)
+	if s := e.counters.Snapshot(); len(buffer.published) != 2 || buffer.published[0][0].Value != 2 || buffer.published[1][0].Value != 3 || s.Buffered != 0 {
+		t.Fatalf("buffer: published %v, counters %+v", buffer.published, s)
+	}
+}
+
+func TestErrorPolicyConfig(t *testing.T) {
+	var o config.OutputConfig
+	if err := json.Unmarshal([]byte(`{"type":"webhook","on_error":"retry"}`), &o); err != nil || o.OnError.Policy != config.OnErrorRetry {
+		t.Fatalf("on_error string form = %+v, %v", o.OnError, err)
+	}
+	if p := newErrorPolicy(o.OnError); p.attempts != defaultRetryAttempts || p.backoff != defaultRetryBackoff {
+		t.Fatalf("policy defaults = %+v", p)
+	}
+	if err := (config.ErrorPolicyConfig{Policy: "ignore"}).Validate(); err == nil {
+		t.Fatal("expected an error for an unknown policy")
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+// The built-in outputs register themselves in the output registry when
+// imported; a third-party output is added by importing its package here.
+import (
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/coap"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/console"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/file"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/graphite"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/homie"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/influxdb"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/journald"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/modbus"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/mqtt"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/nats"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/prometheus"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/sparkplug"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/sqlite"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/statsd"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/syslog"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/unixsock"
+	_ "github.com/ericogr/ads1115-to-mqtt/pkg/output/webhook"
+)
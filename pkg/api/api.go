@@ -0,0 +1,152 @@
+// Package api implements the embedded HTTP API exposing the latest readings,
+// the channel configuration and the process status as JSON.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/latest"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/stats"
+)
+
+// Info describes the running build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+}
+
+// Server is the HTTP API server.
+type Server struct {
+	srv      *http.Server
+	mux      *http.ServeMux
+	store    *latest.Store
+	config   func() config.Config
+	counters *stats.Counters
+	info     Info
+	started  time.Time
+}
+
+// channelResponse is the body of GET /api/channels/{id}.
+type channelResponse struct {
+	Config  config.ChannelConfig `json:"config"`
+	Reading *output.Reading      `json:"reading"`
+}
+
+// statusResponse is the body of GET /api/status.
+type statusResponse struct {
+	Info
+	Started       time.Time `json:"started"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	stats.Snapshot
+}
+
+// New creates the API handlers. cfg returns the running configuration with
+// secrets redacted.
+func New(store *latest.Store, cfg func() config.Config, info Info) *Server {
+	s := &Server{mux: http.NewServeMux(), store: store, config: cfg, counters: stats.Default, info: info, started: time.Now()}
+	s.mux.HandleFunc("GET /api/readings", s.handleReadings)
+	s.mux.HandleFunc("GET /api/channels/{id}", s.handleChannel)
+	s.mux.HandleFunc("GET /api/config", s.handleConfig)
+	s.mux.HandleFunc("GET /api/status", s.handleStatus)
+	return s
+}
+
+// Handle registers an additional handler, for example a streaming endpoint.
+func (s *Server) Handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, h)
+}
+
+// ListenAndServe starts serving on the configured address in the background.
+func (s *Server) ListenAndServe(cfg config.HTTPConfig) error {
+	ln, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return fmt.Errorf("http listen: %w", err)
+	}
+	s.srv = &http.Server{Handler: s.mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("http: serve: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	if s.srv == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleReadings(w http.ResponseWriter, _ *http.Request) {
+	channels := output.NewChannels(s.config().Channels)
+	out := make([]output.Reading, 0)
+	for _, r := range s.store.All() {
+		out = append(out, channels.Reading(r))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleChannel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid channel %q", r.PathValue("id"))
+		return
+	}
+	channels := output.NewChannels(s.config().Channels)
+	_, configured := channels[id]
+	last, ok := s.store.Get(id)
+	if !configured && !ok {
+		writeError(w, http.StatusNotFound, "unknown channel %d", id)
+		return
+	}
+	resp := channelResponse{Config: channels.Lookup(id)}
+	if ok {
+		rd := channels.Reading(last)
+		resp.Reading = &rd
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.config())
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, statusResponse{
+		Info:          s.info,
+		Started:       s.started,
+		UptimeSeconds: time.Since(s.started).Seconds(),
+		Snapshot:      s.counters.Snapshot(),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("http: write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/latest"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/stats"
+)
+
+func newTestServer() *Server {
+	store := latest.New()
+	ts := time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)
+	store.Update([]sensor.Reading{{Channel: 1, Raw: -12, Value: -0.0015, Timestamp: ts}, {Channel: 0, Raw: 19023, Value: 3.72, Timestamp: ts}})
+	cfg := config.Config{
+		Channels: []config.ChannelConfig{{Channel: 0, Enabled: true, Name: "battery"}, {Channel: 1, Enabled: true, Unit: "A"}, {Channel: 2}},
+		Outputs:  []config.OutputConfig{{Type: "mqtt", Options: json.RawMessage(`{"password":"secret"}`)}},
+	}
+	s := New(store, func() config.Config { return cfg.Redacted() }, Info{Version: "1.2.3"})
+	s.counters = &stats.Counters{}
+	s.counters.RecordReadError()
+	return s
+}
+
+func get(t *testing.T, s *Server, path string, wantStatus int, v interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != wantStatus {
+		t.Fatalf("GET %s: status %d, want %d: %s", path, rec.Code, wantStatus, rec.Body.String())
+	}
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("GET %s: decode: %v", path, err)
+		}
+	}
+}
+
+func TestReadings(t *testing.T) {
+	var got []output.Reading
+	get(t, newTestServer(), "/api/readings", http.StatusOK, &got)
+	if len(got) != 2 || got[0].Channel != 0 || got[0].Name != "battery" || got[0].Unit != "V" || got[0].Raw != 19023 || got[1].Unit != "A" {
+		t.Fatalf("unexpected readings %+v", got)
+	}
+}
+
+func TestChannel(t *testing.T) {
+	s := newTestServer()
+	var got channelResponse
+	get(t, s, "/api/channels/0", http.StatusOK, &got)
+	if got.Config.Name != "battery" || got.Reading == nil || got.Reading.Value != 3.72 {
+		t.Fatalf("unexpected channel %+v", got)
+	}
+	got = channelResponse{}
+	get(t, s, "/api/channels/2", http.StatusOK, &got)
+	if got.Reading != nil {
+		t.Fatalf("expected no reading for channel 2, got %+v", got.Reading)
+	}
+	get(t, s, "/api/channels/3", http.StatusNotFound, nil)
+	get(t, s, "/api/channels/x", http.StatusBadRequest, nil)
+}
+
+func TestConfigRedacted(t *testing.T) {
+	var got config.Config
+	get(t, newTestServer(), "/api/config", http.StatusOK, &got)
+	if opts := string(got.Outputs[0].Options); opts != `{"password":"***"}` {
+		t.Fatalf("password not redacted: %s", opts)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	var got statusResponse
+	get(t, newTestServer(), "/api/status", http.StatusOK, &got)
+	if got.Version != "1.2.3" || got.ReadErrors != 1 || got.UptimeSeconds < 0 {
+		t.Fatalf("unexpected status %+v", got)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -16,31 +18,181 @@ const (
 	invalidValueFmtFmt = "invalid value for channel %d: %w"
 )
 
-type MQTTConfig struct {
-	Server   string `json:"server"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	ClientID string `json:"client_id"`
-	// StateTopic is the MQTT topic where the sensor state/value is published.
-	StateTopic string `json:"state_topic"`
-	// DiscoveryTopic is the full topic to publish Home Assistant discovery payload to
-	// (for example: `homeassistant/sensor/machine_battery/config`). If empty, discovery is not published.
-	DiscoveryTopic string `json:"discovery_topic,omitempty"`
-	// Optional discovery payload fields for Home Assistant
-	DiscoveryName     string `json:"discovery_name,omitempty"`
-	DiscoveryUniqueID string `json:"discovery_unique_id,omitempty"`
+// Output error policies.
+const (
+	OnErrorDrop   = "drop"
+	OnErrorRetry  = "retry"
+	OnErrorBuffer = "buffer"
+	OnErrorFatal  = "fatal"
+)
+
+// ErrorPolicyConfig selects what an output does when a publish fails. In
+// JSON it can also be given as the policy name alone ("on_error": "retry").
+type ErrorPolicyConfig struct {
+	// Policy is "drop" (log and drop the snapshot, default), "retry" (retry
+	// with backoff, then drop), "buffer" (keep failed snapshots and publish
+	// them first once the output recovers) or "fatal" (exit the process).
+	Policy string `json:"policy,omitempty"`
+	// Attempts is the number of retries of the retry policy. Default: 3.
+	Attempts int `json:"attempts,omitempty"`
+	// BackoffMs is the delay before the first retry, doubled on each retry
+	// up to 30 seconds. Default: 500.
+	BackoffMs int `json:"backoff_ms,omitempty"`
+	// Buffer is the number of snapshots kept by the buffer policy; the
+	// oldest are dropped when it is full. Default: 100.
+	Buffer int `json:"buffer,omitempty"`
+}
+
+// UnmarshalJSON accepts a policy object or a policy name.
+func (c *ErrorPolicyConfig) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*c = ErrorPolicyConfig{Policy: name}
+		return nil
+	}
+	type plain ErrorPolicyConfig
+	return json.Unmarshal(b, (*plain)(c))
+}
+
+// Validate checks the policy name and its settings.
+func (c ErrorPolicyConfig) Validate() error {
+	switch c.Policy {
+	case "", OnErrorDrop, OnErrorRetry, OnErrorBuffer, OnErrorFatal:
+	default:
+		return fmt.Errorf("invalid policy %q (want drop, retry, buffer or fatal)", c.Policy)
+	}
+	if c.Attempts < 0 || c.BackoffMs < 0 || c.Buffer < 0 {
+		return fmt.Errorf("attempts, backoff_ms and buffer must not be negative")
+	}
+	return nil
 }
 
 type OutputConfig struct {
-	Type       string      `json:"type"`
-	IntervalMs int         `json:"interval_ms,omitempty"`
-	MQTT       *MQTTConfig `json:"mqtt,omitempty"`
+	Type       string `json:"type"`
+	IntervalMs int    `json:"interval_ms,omitempty"`
+	// OnError is the publish error policy. Default: drop.
+	OnError *ErrorPolicyConfig `json:"on_error,omitempty"`
+	// Channels limits the output to these channels; empty means every channel.
+	Channels []int `json:"channels,omitempty"`
+	// ChannelNames overrides the names of channels for this output only.
+	ChannelNames map[int]string `json:"channel_names,omitempty"`
+	// Options holds the settings of the output type, decoded by the factory
+	// registered for the type in the output registry.
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// UnmarshalJSON decodes an output, taking a section named after the type
+// ("mqtt": {...} in an mqtt output), the legacy form of the options, as its
+// options.
+func (o *OutputConfig) UnmarshalJSON(b []byte) error {
+	type plain OutputConfig
+	if err := json.Unmarshal(b, (*plain)(o)); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	typ := strings.ToLower(o.Type)
+	section, ok := fields[typ]
+	if !ok || typ == "options" || string(section) == "null" {
+		return nil
+	}
+	if len(o.Options) > 0 {
+		return fmt.Errorf("output %s: options and a %s section are both set", typ, typ)
+	}
+	o.Options = section
+	return nil
+}
+
+// SetOptions sets top-level options of the output, keeping the others.
+func (o *OutputConfig) SetOptions(values map[string]any) error {
+	opts := make(map[string]any)
+	if len(o.Options) > 0 {
+		if err := json.Unmarshal(o.Options, &opts); err != nil {
+			return fmt.Errorf("options: %w", err)
+		}
+	}
+	for k, v := range values {
+		opts[k] = v
+	}
+	b, err := json.Marshal(opts)
+	if err != nil {
+		return fmt.Errorf("options: %w", err)
+	}
+	o.Options = b
+	return nil
+}
+
+// ValidateChannels checks the channel selection and renaming of the output.
+func (o OutputConfig) ValidateChannels() error {
+	seen := make(map[int]bool)
+	for _, ch := range o.Channels {
+		if ch < 0 || ch > 3 {
+			return fmt.Errorf("channels: invalid channel %d (want 0..3)", ch)
+		}
+		if seen[ch] {
+			return fmt.Errorf("channels: channel %d listed twice", ch)
+		}
+		seen[ch] = true
+	}
+	for ch, name := range o.ChannelNames {
+		if ch < 0 || ch > 3 {
+			return fmt.Errorf("channel_names: invalid channel %d (want 0..3)", ch)
+		}
+		if len(o.Channels) > 0 && !seen[ch] {
+			return fmt.Errorf("channel_names: channel %d is not in channels", ch)
+		}
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("channel_names: empty name for channel %d", ch)
+		}
+	}
+	return nil
+}
+
+// Selects reports whether the output receives channel ch.
+func (o OutputConfig) Selects(ch int) bool {
+	if len(o.Channels) == 0 {
+		return true
+	}
+	for _, c := range o.Channels {
+		if c == ch {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectChannels returns the channels the output receives, with the names
+// overridden by ChannelNames.
+func (o OutputConfig) SelectChannels(channels []ChannelConfig) []ChannelConfig {
+	out := make([]ChannelConfig, 0, len(channels))
+	for _, ch := range channels {
+		if !o.Selects(ch.Channel) {
+			continue
+		}
+		if name, ok := o.ChannelNames[ch.Channel]; ok {
+			ch.Name = name
+		}
+		out = append(out, ch)
+	}
+	return out
 }
 
 // ChannelConfig holds per-channel parameters: enabled, calibration and optional sample rate.
 type ChannelConfig struct {
-	Channel           int     `json:"channel"`
-	Enabled           bool    `json:"enabled"`
+	Channel int  `json:"channel"`
+	Enabled bool `json:"enabled"`
+	// Name is an optional human-friendly channel name used by outputs.
+	Name string `json:"name,omitempty"`
+	// Unit is the unit of the calibrated value. Default: "V".
+	Unit string `json:"unit,omitempty"`
+	// DeviceClass and StateClass are reported in Home Assistant discovery.
+	// Defaults: "voltage" when the unit is V, and "measurement".
+	DeviceClass string `json:"device_class,omitempty"`
+	StateClass  string `json:"state_class,omitempty"`
+	// Precision is the suggested number of decimals when displaying the value.
+	Precision         *int    `json:"precision,omitempty"`
 	SampleRate        int     `json:"sample_rate,omitempty"`
 	CalibrationScale  float64 `json:"calibration_scale,omitempty"`
 	CalibrationOffset float64 `json:"calibration_offset"`
@@ -57,6 +209,110 @@ type Config struct {
 	Outputs    []OutputConfig  `json:"outputs"`
 	SensorType string          `json:"sensor_type"`
 	Channels   []ChannelConfig `json:"channels"`
+	// HTTP enables the embedded HTTP API server.
+	HTTP *HTTPConfig `json:"http,omitempty"`
+	// GRPC enables the gRPC readings server.
+	GRPC *GRPCConfig `json:"grpc,omitempty"`
+	// Path is the JSON file the configuration was loaded from (empty if none).
+	Path string `json:"-"`
+	// OutputsFromFlags reports that the outputs were replaced (-outputs) or
+	// extended (MQTT flags) on the command line, so their indexes may not
+	// match the outputs of the file at Path.
+	OutputsFromFlags bool `json:"-"`
+}
+
+// HTTPConfig holds the settings of the embedded HTTP API server.
+type HTTPConfig struct {
+	// Address is the listen address, e.g. ":8080".
+	Address string `json:"address"`
+}
+
+// Validate checks the listen address.
+func (c HTTPConfig) Validate() error {
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("invalid address %q: %w", c.Address, err)
+	}
+	return nil
+}
+
+// GRPCConfig holds the settings of the gRPC readings server.
+type GRPCConfig struct {
+	// Address is the listen address, e.g. ":9090".
+	Address string `json:"address"`
+}
+
+// Validate checks the listen address.
+func (c GRPCConfig) Validate() error {
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("invalid address %q: %w", c.Address, err)
+	}
+	return nil
+}
+
+// redactedSecret replaces secrets in redacted configurations.
+const redactedSecret = "***"
+
+// Redacted returns a copy of the configuration with secrets (passwords, tokens)
+// replaced so it can be shared safely.
+func (c Config) Redacted() Config {
+	out := c
+	out.Outputs = make([]OutputConfig, len(c.Outputs))
+	for i, o := range c.Outputs {
+		o.Options = redactOptions(o.Options)
+		out.Outputs[i] = o
+	}
+	out.Channels = append([]ChannelConfig(nil), c.Channels...)
+	return out
+}
+
+// isSecretKey reports whether a header or option name holds a credential:
+// authorization, or a name containing password, token, secret or key.
+func isSecretKey(name string) bool {
+	n := strings.ToLower(name)
+	if n == "authorization" {
+		return true
+	}
+	for _, s := range []string{"password", "token", "secret", "key"} {
+		if strings.Contains(n, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactOptions returns a copy of an options blob with the values of
+// credential-like keys redacted at any depth.
+func redactOptions(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return raw
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return raw
+	}
+	b, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return raw
+	}
+	return b
+}
+
+func redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			if s, ok := e.(string); ok && s != "" && isSecretKey(k) {
+				t[k] = redactedSecret
+				continue
+			}
+			t[k] = redactValue(e)
+		}
+	case []any:
+		for i, e := range t {
+			t[i] = redactValue(e)
+		}
+	}
+	return v
 }
 
 func DefaultConfig() Config {
@@ -96,6 +352,8 @@ func LoadFromFlags() (Config, error) {
 	flagDiscoveryTopic := flag.String("mqtt-discovery-topic", "", "MQTT topic to publish Home Assistant discovery payload (full topic)")
 	flagDiscoveryName := flag.String("mqtt-discovery-name", "", "Discovery: sensor name")
 	flagDiscoveryUniqueID := flag.String("mqtt-discovery-unique-id", "", "Discovery: unique_id")
+	flagHTTPAddress := flag.String("http-address", "", "Listen address of the HTTP API (e.g. :8080)")
+	flagGRPCAddress := flag.String("grpc-address", "", "Listen address of the gRPC server (e.g. :9090)")
 
 	flag.Parse()
 
@@ -109,13 +367,9 @@ func LoadFromFlags() (Config, error) {
 	}
 
 	if *cfgPath != "" {
-		b, err := os.ReadFile(*cfgPath)
-		if err != nil {
-			return cfg, fmt.Errorf("read config: %w", err)
-		}
-		// Unmarshal into the new Config shape (channels are per-channel objects).
-		if err := json.Unmarshal(b, &cfg); err != nil {
-			return cfg, fmt.Errorf("parse config: %w", err)
+		var err error
+		if cfg, err = LoadFile(*cfgPath); err != nil {
+			return cfg, err
 		}
 	}
 
@@ -141,6 +395,7 @@ func LoadFromFlags() (Config, error) {
 			outs = append(outs, OutputConfig{Type: p})
 		}
 		cfg.Outputs = outs
+		cfg.OutputsFromFlags = true
 	}
 	// parse output intervals mapping
 	outIntervals := map[string]int{}
@@ -164,68 +419,38 @@ func LoadFromFlags() (Config, error) {
 	}
 	// map mqtt flags into the first mqtt output (create if missing)
 	if *flagMQTTServer != "" || *flagMQTTUser != "" || *flagMQTTPass != "" || *flagClientID != "" || *flagStateTopic != "" || *flagDiscoveryTopic != "" {
+		values := make(map[string]any)
+		for key, v := range map[string]string{
+			"server":              *flagMQTTServer,
+			"username":            *flagMQTTUser,
+			"password":            *flagMQTTPass,
+			"client_id":           *flagClientID,
+			"state_topic":         *flagStateTopic,
+			"discovery_topic":     *flagDiscoveryTopic,
+			"discovery_name":      *flagDiscoveryName,
+			"discovery_unique_id": *flagDiscoveryUniqueID,
+		} {
+			if v != "" {
+				values[key] = v
+			}
+		}
 		// Apply MQTT flags to all mqtt outputs; if none exist, create one.
 		applied := false
 		for i := range cfg.Outputs {
 			if strings.ToLower(cfg.Outputs[i].Type) == "mqtt" {
-				if cfg.Outputs[i].MQTT == nil {
-					cfg.Outputs[i].MQTT = &MQTTConfig{}
-				}
-				if *flagMQTTServer != "" {
-					cfg.Outputs[i].MQTT.Server = *flagMQTTServer
-				}
-				if *flagMQTTUser != "" {
-					cfg.Outputs[i].MQTT.Username = *flagMQTTUser
-				}
-				if *flagMQTTPass != "" {
-					cfg.Outputs[i].MQTT.Password = *flagMQTTPass
-				}
-				if *flagClientID != "" {
-					cfg.Outputs[i].MQTT.ClientID = *flagClientID
-				}
-				if *flagStateTopic != "" {
-					cfg.Outputs[i].MQTT.StateTopic = *flagStateTopic
-				}
-				if *flagDiscoveryTopic != "" {
-					cfg.Outputs[i].MQTT.DiscoveryTopic = *flagDiscoveryTopic
-				}
-				if *flagDiscoveryName != "" {
-					cfg.Outputs[i].MQTT.DiscoveryName = *flagDiscoveryName
-				}
-				if *flagDiscoveryUniqueID != "" {
-					cfg.Outputs[i].MQTT.DiscoveryUniqueID = *flagDiscoveryUniqueID
+				if err := cfg.Outputs[i].SetOptions(values); err != nil {
+					return cfg, fmt.Errorf("outputs[%d].%w", i, err)
 				}
 				applied = true
 			}
 		}
 		if !applied {
-			// create mqtt output config and apply flags
-			mqttOut := OutputConfig{Type: "mqtt", MQTT: &MQTTConfig{}}
-			if *flagMQTTServer != "" {
-				mqttOut.MQTT.Server = *flagMQTTServer
-			}
-			if *flagMQTTUser != "" {
-				mqttOut.MQTT.Username = *flagMQTTUser
-			}
-			if *flagMQTTPass != "" {
-				mqttOut.MQTT.Password = *flagMQTTPass
-			}
-			if *flagClientID != "" {
-				mqttOut.MQTT.ClientID = *flagClientID
-			}
-			if *flagStateTopic != "" {
-				mqttOut.MQTT.StateTopic = *flagStateTopic
-			}
-			if *flagDiscoveryTopic != "" {
-				mqttOut.MQTT.DiscoveryTopic = *flagDiscoveryTopic
-			}
-			if *flagDiscoveryName != "" {
-				mqttOut.MQTT.DiscoveryName = *flagDiscoveryName
-			}
-			if *flagDiscoveryUniqueID != "" {
-				mqttOut.MQTT.DiscoveryUniqueID = *flagDiscoveryUniqueID
+			mqttOut := OutputConfig{Type: "mqtt"}
+			if err := mqttOut.SetOptions(values); err != nil {
+				return cfg, err
 			}
 			cfg.Outputs = append(cfg.Outputs, mqttOut)
+			cfg.OutputsFromFlags = true
 		}
 	}
 	if *flagSensorType != "" {
@@ -313,24 +538,114 @@ func LoadFromFlags() (Config, error) {
 			}
 		}
 	}
+	if *flagHTTPAddress != "" {
+		cfg.HTTP = &HTTPConfig{Address: *flagHTTPAddress}
+	}
+	if cfg.HTTP != nil {
+		if err := cfg.HTTP.Validate(); err != nil {
+			return cfg, fmt.Errorf("http: %w", err)
+		}
+	}
+	if *flagGRPCAddress != "" {
+		cfg.GRPC = &GRPCConfig{Address: *flagGRPCAddress}
+	}
+	if cfg.GRPC != nil {
+		if err := cfg.GRPC.Validate(); err != nil {
+			return cfg, fmt.Errorf("grpc: %w", err)
+		}
+	}
 	// NOTE: outputs[].interval_ms defaulting and sensor interval calculation are handled in the caller (main) based on sample_rate and channels
 
 	// validate sample rate
-	allowed := []int{8, 16, 32, 64, 128, 250, 475, 860}
-	valid := false
-	for _, a := range allowed {
-		if cfg.SampleRate == a {
-			valid = true
-			break
+	if err := ValidateSampleRate(cfg.SampleRate); err != nil {
+		return cfg, err
+	}
+
+	// validate output settings; the options blob is validated by the output registry
+	for i, o := range cfg.Outputs {
+		if err := o.ValidateChannels(); err != nil {
+			return cfg, fmt.Errorf("outputs[%d].%w", i, err)
+		}
+		if o.OnError != nil {
+			if err := o.OnError.Validate(); err != nil {
+				return cfg, fmt.Errorf("outputs[%d].on_error: %w", i, err)
+			}
+		}
+		if b := strings.TrimSpace(string(o.Options)); b != "" && !strings.HasPrefix(b, "{") {
+			return cfg, fmt.Errorf("outputs[%d].options: must be a JSON object", i)
 		}
 	}
-	if !valid {
-		return cfg, fmt.Errorf("invalid sample_rate %d; allowed: %v", cfg.SampleRate, allowed)
+
+	return cfg, nil
+}
+
+// SampleRates lists the ADS1115 sample rates (SPS) accepted in the configuration.
+var SampleRates = []int{8, 16, 32, 64, 128, 250, 475, 860}
+
+// ValidateSampleRate returns an error if sr is not a supported sample rate.
+func ValidateSampleRate(sr int) error {
+	for _, a := range SampleRates {
+		if sr == a {
+			return nil
+		}
 	}
+	return fmt.Errorf("invalid sample_rate %d; allowed: %v", sr, SampleRates)
+}
 
+// UnitOrDefault returns the configured unit or "V" when none is set.
+func (c ChannelConfig) UnitOrDefault() string {
+	if c.Unit == "" {
+		return DefaultUnit
+	}
+	return c.Unit
+}
+
+// DefaultUnit is the unit reported for channels without an explicit unit.
+const DefaultUnit = "V"
+
+// LoadFile loads a JSON configuration file over the defaults.
+func LoadFile(path string) (Config, error) {
+	cfg := DefaultConfig()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("read config: %w", err)
+	}
+	// Unmarshal into the new Config shape (channels are per-channel objects).
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse config: %w", err)
+	}
+	cfg.Path = path
 	return cfg, nil
 }
 
+// Save atomically writes the configuration as indented JSON to path.
+func Save(path string, cfg Config) error {
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*")
+	if err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	// keep the permissions of the file being replaced
+	if fi, err := os.Stat(path); err == nil {
+		_ = os.Chmod(tmp.Name(), fi.Mode().Perm())
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
+
 func parseIntOrHex(s string) (int, error) {
 	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
 		v, err := strconv.ParseInt(s[2:], 16, 0)
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
 )
@@ -70,3 +71,69 @@ func TestParseKeyBoolMap(t *testing.T) {
 		}
 	}
 }
+
+func TestRedacted(t *testing.T) {
+	cfg := Config{Outputs: []OutputConfig{
+		{Type: "mqtt", Options: json.RawMessage(`{"server":"s","password":"p"}`)},
+		{Type: "webhook", Options: json.RawMessage(`{"url":"u","bearer_token":"b","hmac_secret":"h","headers":{"X-Api-Key":"k","X-Site":"lab"}}`)},
+		{Type: "custom", Options: json.RawMessage(`{"url":"u","auth":{"api_token":"t","user":"a"},"password":""}`)},
+	}}
+	r := cfg.Redacted()
+	want := []string{
+		`{"password":"***","server":"s"}`,
+		`{"bearer_token":"***","headers":{"X-Api-Key":"***","X-Site":"lab"},"hmac_secret":"***","url":"u"}`,
+		`{"auth":{"api_token":"***","user":"a"},"password":"","url":"u"}`,
+	}
+	for i, w := range want {
+		if got := string(r.Outputs[i].Options); got != w {
+			t.Fatalf("outputs[%d] options = %s, want %s", i, got, w)
+		}
+	}
+	if string(cfg.Outputs[0].Options) != `{"server":"s","password":"p"}` {
+		t.Fatalf("original configuration modified")
+	}
+}
+
+func TestOutputLegacySection(t *testing.T) {
+	var o OutputConfig
+	if err := json.Unmarshal([]byte(`{"type":"MQTT","interval_ms":5,"mqtt":{"server":"s"}}`), &o); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if o.IntervalMs != 5 || string(o.Options) != `{"server":"s"}` {
+		t.Fatalf("legacy section = %+v", o)
+	}
+	if err := json.Unmarshal([]byte(`{"type":"mqtt","mqtt":{},"options":{}}`), &o); err == nil {
+		t.Fatalf("options and a section both set: no error")
+	}
+	if err := o.SetOptions(map[string]any{"server": "t", "client_id": "c"}); err != nil {
+		t.Fatalf("SetOptions: %v", err)
+	}
+	if got := string(o.Options); got != `{"client_id":"c","server":"t"}` {
+		t.Fatalf("SetOptions = %s", got)
+	}
+}
+
+func TestOutputChannels(t *testing.T) {
+	channels := []ChannelConfig{{Channel: 0, Name: "battery"}, {Channel: 1, Name: "solar"}, {Channel: 2}}
+	o := OutputConfig{Type: "mqtt", Channels: []int{2, 0}, ChannelNames: map[int]string{0: "house battery"}}
+	if err := o.ValidateChannels(); err != nil {
+		t.Fatalf("ValidateChannels: %v", err)
+	}
+	got := o.SelectChannels(channels)
+	if len(got) != 2 || got[0].Name != "house battery" || got[1].Channel != 2 || channels[0].Name != "battery" {
+		t.Fatalf("SelectChannels = %+v", got)
+	}
+	if got := (OutputConfig{ChannelNames: map[int]string{1: "pv"}}).SelectChannels(channels); len(got) != 3 || got[1].Name != "pv" {
+		t.Fatalf("SelectChannels without filter = %+v", got)
+	}
+	for _, bad := range []OutputConfig{
+		{Channels: []int{4}},
+		{Channels: []int{1, 1}},
+		{Channels: []int{1}, ChannelNames: map[int]string{0: "x"}},
+		{ChannelNames: map[int]string{0: " "}},
+	} {
+		if err := bad.ValidateChannels(); err == nil {
+			t.Fatalf("%+v: expected an error", bad)
+		}
+	}
+}
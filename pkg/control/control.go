@@ -0,0 +1,77 @@
+// Package control defines the remote control commands accepted by outputs
+// that can receive requests (for example MQTT command topics).
+package control
+
+import (
+	"fmt"
+	"math"
+)
+
+// Command names.
+const (
+	CmdSetChannel     = "set_channel"
+	CmdSetInterval    = "set_interval"
+	CmdSetCalibration = "set_calibration"
+	CmdReadNow        = "read_now"
+	CmdGetConfig      = "get_config"
+)
+
+// Command is a remote control request. ID is an optional correlation id echoed
+// in the response; the other fields are used depending on Command.
+type Command struct {
+	ID      string `json:"id,omitempty"`
+	Command string `json:"command"`
+	// Channel selects the channel for set_channel and set_calibration.
+	Channel *int `json:"channel,omitempty"`
+	// Enabled enables or disables the channel (set_channel).
+	Enabled *bool `json:"enabled,omitempty"`
+	// SampleRate optionally changes the channel sample rate (set_channel).
+	SampleRate *int `json:"sample_rate,omitempty"`
+	// Output is the index of the output in outputs[] (set_interval).
+	Output *int `json:"output,omitempty"`
+	// IntervalMs is the new publish interval of the output (set_interval).
+	IntervalMs int `json:"interval_ms,omitempty"`
+	// CalibrationScale and CalibrationOffset change the channel calibration (set_calibration).
+	CalibrationScale  *float64 `json:"calibration_scale,omitempty"`
+	CalibrationOffset *float64 `json:"calibration_offset,omitempty"`
+}
+
+// ValidateCalibration checks the values of a set_calibration command: both
+// must be finite and the scale must not be zero.
+func (c Command) ValidateCalibration() error {
+	if v := c.CalibrationScale; v != nil && (*v == 0 || math.IsNaN(*v) || math.IsInf(*v, 0)) {
+		return fmt.Errorf("invalid calibration_scale %v: must be finite and non-zero", *v)
+	}
+	if v := c.CalibrationOffset; v != nil && (math.IsNaN(*v) || math.IsInf(*v, 0)) {
+		return fmt.Errorf("invalid calibration_offset %v: must be finite", *v)
+	}
+	return nil
+}
+
+// Response is the reply to a Command.
+type Response struct {
+	ID     string      `json:"id,omitempty"`
+	OK     bool        `json:"ok"`
+	Error  string      `json:"error,omitempty"`
+	Result interface{} `json:"result,omitempty"`
+}
+
+// Handler executes commands against the running application.
+type Handler interface {
+	Handle(Command) Response
+}
+
+// Commandable is implemented by outputs able to receive commands.
+type Commandable interface {
+	SetCommandHandler(Handler) error
+}
+
+// OK returns a successful response for cmd with an optional result.
+func OK(cmd Command, result interface{}) Response {
+	return Response{ID: cmd.ID, OK: true, Result: result}
+}
+
+// Errorf returns a failed response for cmd.
+func Errorf(cmd Command, format string, args ...interface{}) Response {
+	return Response{ID: cmd.ID, Error: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,682 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: ads1115.proto
+
+package ads1115pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StreamMode int32
+
+const (
+	// Not set: same as STREAM_MODE_RAW.
+	StreamMode_STREAM_MODE_UNSPECIFIED StreamMode = 0
+	// Every sensor read.
+	StreamMode_STREAM_MODE_RAW StreamMode = 1
+	// The aggregated snapshots of the "stream" output.
+	StreamMode_STREAM_MODE_AGGREGATED StreamMode = 2
+)
+
+// Enum value maps for StreamMode.
+var (
+	StreamMode_name = map[int32]string{
+		0: "STREAM_MODE_UNSPECIFIED",
+		1: "STREAM_MODE_RAW",
+		2: "STREAM_MODE_AGGREGATED",
+	}
+	StreamMode_value = map[string]int32{
+		"STREAM_MODE_UNSPECIFIED": 0,
+		"STREAM_MODE_RAW":         1,
+		"STREAM_MODE_AGGREGATED":  2,
+	}
+)
+
+func (x StreamMode) Enum() *StreamMode {
+	p := new(StreamMode)
+	*p = x
+	return p
+}
+
+func (x StreamMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StreamMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_ads1115_proto_enumTypes[0].Descriptor()
+}
+
+func (StreamMode) Type() protoreflect.EnumType {
+	return &file_ads1115_proto_enumTypes[0]
+}
+
+func (x StreamMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StreamMode.Descriptor instead.
+func (StreamMode) EnumDescriptor() ([]byte, []int) {
+	return file_ads1115_proto_rawDescGZIP(), []int{0}
+}
+
+// Reading mirrors sensor.Reading.
+type Reading struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Channel int32                  `protobuf:"varint,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	// raw is the ADS1115 conversion result (int16).
+	Raw int32 `protobuf:"varint,2,opt,name=raw,proto3" json:"raw,omitempty"`
+	// value is the calibrated value: raw voltage * calibration_scale + calibration_offset.
+	Value         float64                `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reading) Reset() {
+	*x = Reading{}
+	mi := &file_ads1115_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reading) ProtoMessage() {}
+
+func (x *Reading) ProtoReflect() protoreflect.Message {
+	mi := &file_ads1115_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reading.ProtoReflect.Descriptor instead.
+func (*Reading) Descriptor() ([]byte, []int) {
+	return file_ads1115_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Reading) GetChannel() int32 {
+	if x != nil {
+		return x.Channel
+	}
+	return 0
+}
+
+func (x *Reading) GetRaw() int32 {
+	if x != nil {
+		return x.Raw
+	}
+	return 0
+}
+
+func (x *Reading) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Reading) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// ChannelConfig mirrors config.ChannelConfig.
+type ChannelConfig struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Channel int32                  `protobuf:"varint,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Enabled bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Name    string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// unit defaults to "V".
+	Unit        string `protobuf:"bytes,4,opt,name=unit,proto3" json:"unit,omitempty"`
+	DeviceClass string `protobuf:"bytes,5,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	StateClass  string `protobuf:"bytes,6,opt,name=state_class,json=stateClass,proto3" json:"state_class,omitempty"`
+	// precision is the suggested number of decimals, when set.
+	Precision         *int32  `protobuf:"varint,7,opt,name=precision,proto3,oneof" json:"precision,omitempty"`
+	SampleRate        int32   `protobuf:"varint,8,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`
+	CalibrationScale  float64 `protobuf:"fixed64,9,opt,name=calibration_scale,json=calibrationScale,proto3" json:"calibration_scale,omitempty"`
+	CalibrationOffset float64 `protobuf:"fixed64,10,opt,name=calibration_offset,json=calibrationOffset,proto3" json:"calibration_offset,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ChannelConfig) Reset() {
+	*x = ChannelConfig{}
+	mi := &file_ads1115_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChannelConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelConfig) ProtoMessage() {}
+
+func (x *ChannelConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_ads1115_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelConfig.ProtoReflect.Descriptor instead.
+func (*ChannelConfig) Descriptor() ([]byte, []int) {
+	return file_ads1115_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ChannelConfig) GetChannel() int32 {
+	if x != nil {
+		return x.Channel
+	}
+	return 0
+}
+
+func (x *ChannelConfig) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *ChannelConfig) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ChannelConfig) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *ChannelConfig) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *ChannelConfig) GetStateClass() string {
+	if x != nil {
+		return x.StateClass
+	}
+	return ""
+}
+
+func (x *ChannelConfig) GetPrecision() int32 {
+	if x != nil && x.Precision != nil {
+		return *x.Precision
+	}
+	return 0
+}
+
+func (x *ChannelConfig) GetSampleRate() int32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *ChannelConfig) GetCalibrationScale() float64 {
+	if x != nil {
+		return x.CalibrationScale
+	}
+	return 0
+}
+
+func (x *ChannelConfig) GetCalibrationOffset() float64 {
+	if x != nil {
+		return x.CalibrationOffset
+	}
+	return 0
+}
+
+type StreamReadingsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Mode  StreamMode             `protobuf:"varint,1,opt,name=mode,proto3,enum=ads1115.v1.StreamMode" json:"mode,omitempty"`
+	// channels selects the channels to receive; empty means all.
+	Channels      []int32 `protobuf:"varint,2,rep,packed,name=channels,proto3" json:"channels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamReadingsRequest) Reset() {
+	*x = StreamReadingsRequest{}
+	mi := &file_ads1115_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamReadingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamReadingsRequest) ProtoMessage() {}
+
+func (x *StreamReadingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ads1115_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamReadingsRequest.ProtoReflect.Descriptor instead.
+func (*StreamReadingsRequest) Descriptor() ([]byte, []int) {
+	return file_ads1115_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StreamReadingsRequest) GetMode() StreamMode {
+	if x != nil {
+		return x.Mode
+	}
+	return StreamMode_STREAM_MODE_UNSPECIFIED
+}
+
+func (x *StreamReadingsRequest) GetChannels() []int32 {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+// Snapshot is a set of readings published together.
+type Snapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mode          StreamMode             `protobuf:"varint,1,opt,name=mode,proto3,enum=ads1115.v1.StreamMode" json:"mode,omitempty"`
+	Readings      []*Reading             `protobuf:"bytes,2,rep,name=readings,proto3" json:"readings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_ads1115_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_ads1115_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_ads1115_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Snapshot) GetMode() StreamMode {
+	if x != nil {
+		return x.Mode
+	}
+	return StreamMode_STREAM_MODE_UNSPECIFIED
+}
+
+func (x *Snapshot) GetReadings() []*Reading {
+	if x != nil {
+		return x.Readings
+	}
+	return nil
+}
+
+type GetLatestRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// channels selects the channels to return; empty means all.
+	Channels      []int32 `protobuf:"varint,1,rep,packed,name=channels,proto3" json:"channels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestRequest) Reset() {
+	*x = GetLatestRequest{}
+	mi := &file_ads1115_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestRequest) ProtoMessage() {}
+
+func (x *GetLatestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ads1115_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestRequest.ProtoReflect.Descriptor instead.
+func (*GetLatestRequest) Descriptor() ([]byte, []int) {
+	return file_ads1115_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetLatestRequest) GetChannels() []int32 {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+type GetLatestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Readings      []*Reading             `protobuf:"bytes,1,rep,name=readings,proto3" json:"readings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestResponse) Reset() {
+	*x = GetLatestResponse{}
+	mi := &file_ads1115_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestResponse) ProtoMessage() {}
+
+func (x *GetLatestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ads1115_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestResponse.ProtoReflect.Descriptor instead.
+func (*GetLatestResponse) Descriptor() ([]byte, []int) {
+	return file_ads1115_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetLatestResponse) GetReadings() []*Reading {
+	if x != nil {
+		return x.Readings
+	}
+	return nil
+}
+
+type GetConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigRequest) Reset() {
+	*x = GetConfigRequest{}
+	mi := &file_ads1115_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigRequest) ProtoMessage() {}
+
+func (x *GetConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ads1115_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetConfigRequest) Descriptor() ([]byte, []int) {
+	return file_ads1115_proto_rawDescGZIP(), []int{6}
+}
+
+type GetConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SensorType    string                 `protobuf:"bytes,1,opt,name=sensor_type,json=sensorType,proto3" json:"sensor_type,omitempty"`
+	SampleRate    int32                  `protobuf:"varint,2,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`
+	Channels      []*ChannelConfig       `protobuf:"bytes,3,rep,name=channels,proto3" json:"channels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigResponse) Reset() {
+	*x = GetConfigResponse{}
+	mi := &file_ads1115_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigResponse) ProtoMessage() {}
+
+func (x *GetConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ads1115_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetConfigResponse) Descriptor() ([]byte, []int) {
+	return file_ads1115_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetConfigResponse) GetSensorType() string {
+	if x != nil {
+		return x.SensorType
+	}
+	return ""
+}
+
+func (x *GetConfigResponse) GetSampleRate() int32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *GetConfigResponse) GetChannels() []*ChannelConfig {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+var File_ads1115_proto protoreflect.FileDescriptor
+
+const file_ads1115_proto_rawDesc = "" +
+	"\n" +
+	"\rads1115.proto\x12\n" +
+	"ads1115.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x85\x01\n" +
+	"\aReading\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\x05R\achannel\x12\x10\n" +
+	"\x03raw\x18\x02 \x01(\x05R\x03raw\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\x01R\x05value\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xdd\x02\n" +
+	"\rChannelConfig\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\x05R\achannel\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04unit\x18\x04 \x01(\tR\x04unit\x12!\n" +
+	"\fdevice_class\x18\x05 \x01(\tR\vdeviceClass\x12\x1f\n" +
+	"\vstate_class\x18\x06 \x01(\tR\n" +
+	"stateClass\x12!\n" +
+	"\tprecision\x18\a \x01(\x05H\x00R\tprecision\x88\x01\x01\x12\x1f\n" +
+	"\vsample_rate\x18\b \x01(\x05R\n" +
+	"sampleRate\x12+\n" +
+	"\x11calibration_scale\x18\t \x01(\x01R\x10calibrationScale\x12-\n" +
+	"\x12calibration_offset\x18\n" +
+	" \x01(\x01R\x11calibrationOffsetB\f\n" +
+	"\n" +
+	"_precision\"_\n" +
+	"\x15StreamReadingsRequest\x12*\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x16.ads1115.v1.StreamModeR\x04mode\x12\x1a\n" +
+	"\bchannels\x18\x02 \x03(\x05R\bchannels\"g\n" +
+	"\bSnapshot\x12*\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x16.ads1115.v1.StreamModeR\x04mode\x12/\n" +
+	"\breadings\x18\x02 \x03(\v2\x13.ads1115.v1.ReadingR\breadings\".\n" +
+	"\x10GetLatestRequest\x12\x1a\n" +
+	"\bchannels\x18\x01 \x03(\x05R\bchannels\"D\n" +
+	"\x11GetLatestResponse\x12/\n" +
+	"\breadings\x18\x01 \x03(\v2\x13.ads1115.v1.ReadingR\breadings\"\x12\n" +
+	"\x10GetConfigRequest\"\x8c\x01\n" +
+	"\x11GetConfigResponse\x12\x1f\n" +
+	"\vsensor_type\x18\x01 \x01(\tR\n" +
+	"sensorType\x12\x1f\n" +
+	"\vsample_rate\x18\x02 \x01(\x05R\n" +
+	"sampleRate\x125\n" +
+	"\bchannels\x18\x03 \x03(\v2\x19.ads1115.v1.ChannelConfigR\bchannels*Z\n" +
+	"\n" +
+	"StreamMode\x12\x1b\n" +
+	"\x17STREAM_MODE_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fSTREAM_MODE_RAW\x10\x01\x12\x1a\n" +
+	"\x16STREAM_MODE_AGGREGATED\x10\x022\xf1\x01\n" +
+	"\x0eReadingService\x12K\n" +
+	"\x0eStreamReadings\x12!.ads1115.v1.StreamReadingsRequest\x1a\x14.ads1115.v1.Snapshot0\x01\x12H\n" +
+	"\tGetLatest\x12\x1c.ads1115.v1.GetLatestRequest\x1a\x1d.ads1115.v1.GetLatestResponse\x12H\n" +
+	"\tGetConfig\x12\x1c.ads1115.v1.GetConfigRequest\x1a\x1d.ads1115.v1.GetConfigResponseB:Z8github.com/ericogr/ads1115-to-mqtt/pkg/grpcapi/ads1115pbb\x06proto3"
+
+var (
+	file_ads1115_proto_rawDescOnce sync.Once
+	file_ads1115_proto_rawDescData []byte
+)
+
+func file_ads1115_proto_rawDescGZIP() []byte {
+	file_ads1115_proto_rawDescOnce.Do(func() {
+		file_ads1115_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ads1115_proto_rawDesc), len(file_ads1115_proto_rawDesc)))
+	})
+	return file_ads1115_proto_rawDescData
+}
+
+var file_ads1115_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_ads1115_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_ads1115_proto_goTypes = []any{
+	(StreamMode)(0),               // 0: ads1115.v1.StreamMode
+	(*Reading)(nil),               // 1: ads1115.v1.Reading
+	(*ChannelConfig)(nil),         // 2: ads1115.v1.ChannelConfig
+	(*StreamReadingsRequest)(nil), // 3: ads1115.v1.StreamReadingsRequest
+	(*Snapshot)(nil),              // 4: ads1115.v1.Snapshot
+	(*GetLatestRequest)(nil),      // 5: ads1115.v1.GetLatestRequest
+	(*GetLatestResponse)(nil),     // 6: ads1115.v1.GetLatestResponse
+	(*GetConfigRequest)(nil),      // 7: ads1115.v1.GetConfigRequest
+	(*GetConfigResponse)(nil),     // 8: ads1115.v1.GetConfigResponse
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+}
+var file_ads1115_proto_depIdxs = []int32{
+	9, // 0: ads1115.v1.Reading.timestamp:type_name -> google.protobuf.Timestamp
+	0, // 1: ads1115.v1.StreamReadingsRequest.mode:type_name -> ads1115.v1.StreamMode
+	0, // 2: ads1115.v1.Snapshot.mode:type_name -> ads1115.v1.StreamMode
+	1, // 3: ads1115.v1.Snapshot.readings:type_name -> ads1115.v1.Reading
+	1, // 4: ads1115.v1.GetLatestResponse.readings:type_name -> ads1115.v1.Reading
+	2, // 5: ads1115.v1.GetConfigResponse.channels:type_name -> ads1115.v1.ChannelConfig
+	3, // 6: ads1115.v1.ReadingService.StreamReadings:input_type -> ads1115.v1.StreamReadingsRequest
+	5, // 7: ads1115.v1.ReadingService.GetLatest:input_type -> ads1115.v1.GetLatestRequest
+	7, // 8: ads1115.v1.ReadingService.GetConfig:input_type -> ads1115.v1.GetConfigRequest
+	4, // 9: ads1115.v1.ReadingService.StreamReadings:output_type -> ads1115.v1.Snapshot
+	6, // 10: ads1115.v1.ReadingService.GetLatest:output_type -> ads1115.v1.GetLatestResponse
+	8, // 11: ads1115.v1.ReadingService.GetConfig:output_type -> ads1115.v1.GetConfigResponse
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_ads1115_proto_init() }
+func file_ads1115_proto_init() {
+	if File_ads1115_proto != nil {
+		return
+	}
+	file_ads1115_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ads1115_proto_rawDesc), len(file_ads1115_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ads1115_proto_goTypes,
+		DependencyIndexes: file_ads1115_proto_depIdxs,
+		EnumInfos:         file_ads1115_proto_enumTypes,
+		MessageInfos:      file_ads1115_proto_msgTypes,
+	}.Build()
+	File_ads1115_proto = out.File
+	file_ads1115_proto_goTypes = nil
+	file_ads1115_proto_depIdxs = nil
+}
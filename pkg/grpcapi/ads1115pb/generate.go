@@ -0,0 +1,5 @@
+// Package ads1115pb holds the protobuf messages and gRPC service of the
+// readings API generated from ads1115.proto.
+package ads1115pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ads1115.proto
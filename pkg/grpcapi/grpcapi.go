@@ -0,0 +1,176 @@
+// Package grpcapi implements the optional gRPC server streaming readings and
+// exposing the latest readings and the channel configuration
+// (service ads1115.v1.ReadingService, see ads1115pb/ads1115.proto).
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/grpcapi/ads1115pb"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/latest"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/stream"
+)
+
+// numChannels is the number of ADS1115 input channels.
+const numChannels = 4
+
+// Server is the gRPC readings server.
+type Server struct {
+	ads1115pb.UnimplementedReadingServiceServer
+	srv    *grpc.Server
+	store  *latest.Store
+	config func() config.Config
+	hub    *stream.Hub
+}
+
+// New creates the server. Streams subscribe to hub; cfg returns the running
+// configuration.
+func New(store *latest.Store, cfg func() config.Config, hub *stream.Hub) *Server {
+	s := &Server{srv: grpc.NewServer(), store: store, config: cfg, hub: hub}
+	ads1115pb.RegisterReadingServiceServer(s.srv, s)
+	return s
+}
+
+// ListenAndServe starts serving on the configured address in the background.
+func (s *Server) ListenAndServe(cfg config.GRPCConfig) error {
+	ln, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return fmt.Errorf("grpc listen: %w", err)
+	}
+	go s.Serve(ln)
+	return nil
+}
+
+// Serve serves on ln until the server is closed.
+func (s *Server) Serve(ln net.Listener) {
+	if err := s.srv.Serve(ln); err != nil {
+		log.Printf("grpc: serve: %v", err)
+	}
+}
+
+// Close stops the server, ending the open streams.
+func (s *Server) Close() {
+	s.srv.Stop()
+}
+
+// StreamReadings sends the published readings until the client cancels the
+// call. A client that falls behind is disconnected with ResourceExhausted.
+// Aggregated streams fail with FailedPrecondition without a "stream" output.
+func (s *Server) StreamReadings(req *ads1115pb.StreamReadingsRequest, st grpc.ServerStreamingServer[ads1115pb.Snapshot]) error {
+	var kind string
+	mode := req.GetMode()
+	switch mode {
+	case ads1115pb.StreamMode_STREAM_MODE_UNSPECIFIED, ads1115pb.StreamMode_STREAM_MODE_RAW:
+		kind, mode = stream.KindRaw, ads1115pb.StreamMode_STREAM_MODE_RAW
+	case ads1115pb.StreamMode_STREAM_MODE_AGGREGATED:
+		if !hasStreamOutput(s.config()) {
+			return status.Error(codes.FailedPrecondition, `aggregated mode requires a "stream" output`)
+		}
+		kind = stream.KindSnapshot
+	default:
+		return status.Errorf(codes.InvalidArgument, "invalid mode %v", req.GetMode())
+	}
+	channels, err := channelFilter(req.GetChannels())
+	if err != nil {
+		return err
+	}
+	sub := s.hub.Subscribe(kind, channels)
+	defer sub.Close()
+	for {
+		select {
+		case readings := <-sub.Readings():
+			if err := st.Send(&ads1115pb.Snapshot{Mode: mode, Readings: toProto(readings)}); err != nil {
+				return err
+			}
+		case <-sub.Dropped():
+			return status.Error(codes.ResourceExhausted, "client too slow: stream dropped")
+		case <-st.Context().Done():
+			return nil
+		}
+	}
+}
+
+// hasStreamOutput reports whether cfg has an output feeding the aggregated
+// snapshots of the hub.
+func hasStreamOutput(cfg config.Config) bool {
+	for _, o := range cfg.Outputs {
+		if strings.EqualFold(o.Type, "stream") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLatest returns the latest reading of the requested channels.
+func (s *Server) GetLatest(_ context.Context, req *ads1115pb.GetLatestRequest) (*ads1115pb.GetLatestResponse, error) {
+	channels, err := channelFilter(req.GetChannels())
+	if err != nil {
+		return nil, err
+	}
+	var readings []sensor.Reading
+	for _, r := range s.store.All() {
+		if channels == nil || channels[r.Channel] {
+			readings = append(readings, r)
+		}
+	}
+	return &ads1115pb.GetLatestResponse{Readings: toProto(readings)}, nil
+}
+
+// GetConfig returns the sensor settings and the channel configuration.
+func (s *Server) GetConfig(context.Context, *ads1115pb.GetConfigRequest) (*ads1115pb.GetConfigResponse, error) {
+	cfg := s.config()
+	resp := &ads1115pb.GetConfigResponse{SensorType: cfg.SensorType, SampleRate: int32(cfg.SampleRate)}
+	for _, ch := range cfg.Channels {
+		pc := &ads1115pb.ChannelConfig{
+			Channel:           int32(ch.Channel),
+			Enabled:           ch.Enabled,
+			Name:              ch.Name,
+			Unit:              ch.UnitOrDefault(),
+			DeviceClass:       ch.DeviceClass,
+			StateClass:        ch.StateClass,
+			SampleRate:        int32(ch.SampleRate),
+			CalibrationScale:  ch.CalibrationScale,
+			CalibrationOffset: ch.CalibrationOffset,
+		}
+		if ch.Precision != nil {
+			p := int32(*ch.Precision)
+			pc.Precision = &p
+		}
+		resp.Channels = append(resp.Channels, pc)
+	}
+	return resp, nil
+}
+
+// channelFilter returns the requested channels as a set, nil for all channels.
+func channelFilter(channels []int32) (map[int]bool, error) {
+	if len(channels) == 0 {
+		return nil, nil
+	}
+	set := make(map[int]bool, len(channels))
+	for _, ch := range channels {
+		if ch < 0 || ch >= numChannels {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid channel %d", ch)
+		}
+		set[int(ch)] = true
+	}
+	return set, nil
+}
+
+func toProto(readings []sensor.Reading) []*ads1115pb.Reading {
+	out := make([]*ads1115pb.Reading, 0, len(readings))
+	for _, r := range readings {
+		out = append(out, &ads1115pb.Reading{Channel: int32(r.Channel), Raw: int32(r.Raw), Value: r.Value, Timestamp: timestamppb.New(r.Timestamp)})
+	}
+	return out
+}
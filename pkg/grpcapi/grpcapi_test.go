@@ -0,0 +1,137 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/grpcapi/ads1115pb"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/latest"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/stream"
+)
+
+var testTS = time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)
+
+// newClient serves s over an in-memory listener and returns a client.
+func newClient(t *testing.T, s *Server) ads1115pb.ReadingServiceClient {
+	t.Helper()
+	ln := bufconn.Listen(1 << 16)
+	go s.Serve(ln)
+	t.Cleanup(s.Close)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return ads1115pb.NewReadingServiceClient(conn)
+}
+
+func TestGetLatestAndConfig(t *testing.T) {
+	store := latest.New()
+	store.Update([]sensor.Reading{{Channel: 0, Raw: 19023, Value: 3.72, Timestamp: testTS}, {Channel: 2, Raw: -12, Value: -0.0015, Timestamp: testTS}})
+	precision := 2
+	cfg := config.Config{SensorType: "simulation", SampleRate: 128, Channels: []config.ChannelConfig{{Channel: 0, Enabled: true, Name: "battery", Precision: &precision, CalibrationScale: 2}}}
+	c := newClient(t, New(store, func() config.Config { return cfg }, stream.NewHub(4)))
+	ctx := context.Background()
+
+	resp, err := c.GetLatest(ctx, &ads1115pb.GetLatestRequest{Channels: []int32{2}})
+	if err != nil {
+		t.Fatalf("GetLatest: %v", err)
+	}
+	if len(resp.Readings) != 1 || resp.Readings[0].Raw != -12 || resp.Readings[0].Value != -0.0015 || !resp.Readings[0].Timestamp.AsTime().Equal(testTS) {
+		t.Fatalf("unexpected readings %v", resp.Readings)
+	}
+	if _, err := c.GetLatest(ctx, &ads1115pb.GetLatestRequest{Channels: []int32{4}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("invalid channel: got %v", err)
+	}
+
+	cr, err := c.GetConfig(ctx, &ads1115pb.GetConfigRequest{})
+	if err != nil {
+		t.Fatalf("GetConfig: %v", err)
+	}
+	ch := cr.Channels[0]
+	if cr.SensorType != "simulation" || cr.SampleRate != 128 || ch.Name != "battery" || ch.Unit != "V" || ch.GetPrecision() != 2 || ch.CalibrationScale != 2 || !ch.Enabled {
+		t.Fatalf("unexpected config %v", cr)
+	}
+}
+
+func TestStreamReadings(t *testing.T) {
+	hub := stream.NewHub(4)
+	cfg := config.Config{}
+	c := newClient(t, New(latest.New(), func() config.Config { return cfg }, hub))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// without a "stream" output no aggregated snapshot would ever be sent
+	st, err := c.StreamReadings(ctx, &ads1115pb.StreamReadingsRequest{Mode: ads1115pb.StreamMode_STREAM_MODE_AGGREGATED})
+	if err == nil {
+		_, err = st.Recv()
+	}
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("aggregated without a stream output: got %v", err)
+	}
+	cfg.Outputs = []config.OutputConfig{{Type: "stream"}}
+	st, err = c.StreamReadings(ctx, &ads1115pb.StreamReadingsRequest{Mode: ads1115pb.StreamMode_STREAM_MODE_AGGREGATED, Channels: []int32{1}})
+	if err != nil {
+		t.Fatalf("StreamReadings: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.Clients() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream not subscribed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	hub.Publish(stream.KindRaw, []sensor.Reading{{Channel: 1, Value: 9, Timestamp: testTS}})
+	hub.Publish(stream.KindSnapshot, []sensor.Reading{{Channel: 0, Value: 1, Timestamp: testTS}, {Channel: 1, Raw: 2, Value: 0.5, Timestamp: testTS}})
+	snap, err := st.Recv()
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	if snap.Mode != ads1115pb.StreamMode_STREAM_MODE_AGGREGATED || len(snap.Readings) != 1 || snap.Readings[0].Channel != 1 || snap.Readings[0].Value != 0.5 {
+		t.Fatalf("unexpected snapshot %v", snap)
+	}
+	cancel()
+	for hub.Clients() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream not unsubscribed after cancel")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStreamReadingsUnspecifiedMode(t *testing.T) {
+	hub := stream.NewHub(4)
+	c := newClient(t, New(latest.New(), func() config.Config { return config.Config{} }, hub))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	st, err := c.StreamReadings(ctx, &ads1115pb.StreamReadingsRequest{})
+	if err != nil {
+		t.Fatalf("StreamReadings: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.Clients() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream not subscribed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	hub.Publish(stream.KindRaw, []sensor.Reading{{Channel: 3, Value: 9, Timestamp: testTS}})
+	snap, err := st.Recv()
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	if snap.Mode != ads1115pb.StreamMode_STREAM_MODE_RAW || len(snap.Readings) != 1 || snap.Readings[0].Value != 9 {
+		t.Fatalf("unexpected snapshot %v", snap)
+	}
+}
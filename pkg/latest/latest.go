@@ -0,0 +1,52 @@
+// Package latest keeps the most recent reading of every channel so servers
+// can answer queries without touching the sensor.
+package latest
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+// Store holds the latest reading per channel. It is safe for concurrent use.
+type Store struct {
+	mu       sync.RWMutex
+	readings map[int]sensor.Reading
+}
+
+func New() *Store {
+	return &Store{readings: make(map[int]sensor.Reading)}
+}
+
+// Update records readings, replacing older readings of the same channels.
+func (s *Store) Update(readings []sensor.Reading) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range readings {
+		if cur, ok := s.readings[r.Channel]; ok && r.Timestamp.Before(cur.Timestamp) {
+			continue
+		}
+		s.readings[r.Channel] = r
+	}
+}
+
+// All returns the latest reading of every channel ordered by channel.
+func (s *Store) All() []sensor.Reading {
+	s.mu.RLock()
+	out := make([]sensor.Reading, 0, len(s.readings))
+	for _, r := range s.readings {
+		out = append(out, r)
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Channel < out[j].Channel })
+	return out
+}
+
+// Get returns the latest reading of a channel.
+func (s *Store) Get(channel int) (sensor.Reading, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.readings[channel]
+	return r, ok
+}
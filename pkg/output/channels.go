@@ -0,0 +1,45 @@
+package output
+
+import (
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+// Channels indexes the channel configurations of an output by channel number.
+type Channels map[int]config.ChannelConfig
+
+func NewChannels(channels []config.ChannelConfig) Channels {
+	c := make(Channels, len(channels))
+	for _, ch := range channels {
+		c[ch.Channel] = ch
+	}
+	return c
+}
+
+// Lookup returns the configuration of channel ch, or one with only the
+// channel number set when ch is not configured.
+func (c Channels) Lookup(ch int) config.ChannelConfig {
+	if cfg, ok := c[ch]; ok {
+		return cfg
+	}
+	return config.ChannelConfig{Channel: ch}
+}
+
+// Reading is a reading with its channel metadata, as encoded by the JSON
+// outputs.
+type Reading struct {
+	Channel   int       `json:"channel"`
+	Name      string    `json:"name,omitempty"`
+	Unit      string    `json:"unit"`
+	Value     float64   `json:"value"`
+	Raw       int16     `json:"raw"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Reading returns r with the metadata of its channel.
+func (c Channels) Reading(r sensor.Reading) Reading {
+	ch := c.Lookup(r.Channel)
+	return Reading{Channel: r.Channel, Name: ch.Name, Unit: ch.UnitOrDefault(), Value: r.Value, Raw: r.Raw, Timestamp: r.Timestamp}
+}
@@ -0,0 +1,24 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+func TestChannels(t *testing.T) {
+	channels := NewChannels([]config.ChannelConfig{{Channel: 0, Name: "battery", Unit: "A"}})
+	if ch := channels.Lookup(2); ch.Channel != 2 || ch.Name != "" {
+		t.Fatalf("unconfigured channel = %+v", ch)
+	}
+	ts := time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)
+	got := channels.Reading(sensor.Reading{Channel: 0, Raw: 19023, Value: 3.72, Timestamp: ts})
+	if got != (Reading{Channel: 0, Name: "battery", Unit: "A", Value: 3.72, Raw: 19023, Timestamp: ts}) {
+		t.Fatalf("reading = %+v", got)
+	}
+	if got := channels.Reading(sensor.Reading{Channel: 3}); got.Unit != "V" || got.Name != "" {
+		t.Fatalf("unconfigured reading = %+v", got)
+	}
+}
@@ -0,0 +1,50 @@
+package coap
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Minimal CBOR (RFC 8949) encoding of the resource representation: a map of
+// text keys to unsigned/negative integers, text strings and float64 values.
+
+const (
+	majorUint   = 0
+	majorNegint = 1
+	majorText   = 3
+	majorMap    = 5
+	majorTag    = 6
+	float64Head = 0xfb
+	tagEpoch    = 1
+)
+
+func cborHead(b []byte, major byte, v uint64) []byte {
+	m := major << 5
+	switch {
+	case v < 24:
+		return append(b, m|byte(v))
+	case v <= math.MaxUint8:
+		return append(b, m|24, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, m|25), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, m|26), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, m|27), v)
+	}
+}
+
+func cborInt(b []byte, v int64) []byte {
+	if v < 0 {
+		return cborHead(b, majorNegint, uint64(-1-v))
+	}
+	return cborHead(b, majorUint, uint64(v))
+}
+
+func cborText(b []byte, s string) []byte {
+	return append(cborHead(b, majorText, uint64(len(s))), s...)
+}
+
+func cborFloat(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, float64Head), math.Float64bits(v))
+}
@@ -0,0 +1,458 @@
+// Package coap implements an output running a CoAP server (RFC 7252) that
+// serves each channel as an observable resource (RFC 7641) in CBOR or JSON,
+// pushes a notification to the observers on every snapshot and lists the
+// resources in CoRE link format at /.well-known/core (RFC 6690).
+package coap
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	mathrand "math/rand/v2"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	DefaultAddress      = ":5683"
+	DefaultPathPrefix   = "channels"
+	DefaultMaxObservers = 64
+
+	wellKnownCore = "/.well-known/core"
+	resourceType  = "ads1115.channel"
+	// conEvery makes every n-th notification confirmable; an observer that
+	// does not acknowledge it after the retransmissions is removed.
+	conEvery      = 20
+	maxDatagram   = 1152
+	observeSeqMax = 1 << 24
+
+	// transmission parameters of confirmable messages (RFC 7252 section 4.8)
+	ackTimeout      = 2 * time.Second
+	ackRandomFactor = 1.5
+	maxRetransmit   = 4
+)
+
+// representation is the state of a channel resource.
+type representation struct {
+	Channel   int        `json:"channel"`
+	Name      string     `json:"name,omitempty"`
+	Unit      string     `json:"unit"`
+	Value     *float64   `json:"value,omitempty"`
+	Raw       *int16     `json:"raw,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// observer is a registered observation of a channel resource.
+type observer struct {
+	addr    net.Addr
+	token   []byte
+	channel int
+	format  int
+	sent    int
+	lastID  uint16 // message id of the last notification
+	pending bool   // a confirmable notification awaits its acknowledgement
+	conID   uint16 // message id of that notification
+	timer   *time.Timer
+}
+
+type CoAPOutput struct {
+	conn     net.PacketConn
+	prefix   string
+	format   int
+	max      int
+	channels output.Channels
+
+	mu        sync.Mutex
+	latest    map[int]sensor.Reading
+	seq       map[int]uint32
+	observers map[string]*observer
+	msgID     uint16
+	done      chan struct{}
+	// ackTimeout is the initial retransmission timeout of confirmable notifications.
+	ackTimeout time.Duration
+}
+
+func init() {
+	output.Register("coap", output.Registration{
+		Options: func() any { return &Config{} },
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewCoAP(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+// NewCoAP starts the server on the configured UDP address.
+func NewCoAP(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Address == "" {
+		cfg.Address = DefaultAddress
+	}
+	conn, err := net.ListenPacket("udp", cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("coap listen: %w", err)
+	}
+	c := newCoAP(cfg, channels, conn)
+	go c.serve()
+	return c, nil
+}
+
+func newCoAP(cfg Config, channels []config.ChannelConfig, conn net.PacketConn) *CoAPOutput {
+	c := &CoAPOutput{
+		conn:      conn,
+		prefix:    "/" + cfg.PathPrefix,
+		format:    formatCBOR,
+		max:       cfg.MaxObservers,
+		channels:  output.NewChannels(channels),
+		latest:    make(map[int]sensor.Reading),
+		seq:       make(map[int]uint32),
+		observers: make(map[string]*observer),
+		done:      make(chan struct{}),
+
+		ackTimeout: ackTimeout,
+	}
+	if cfg.PathPrefix == "" {
+		c.prefix = "/" + DefaultPathPrefix
+	}
+	if cfg.ContentFormat == ContentFormatJSON {
+		c.format = formatJSON
+	}
+	if c.max == 0 {
+		c.max = DefaultMaxObservers
+	}
+	var id [2]byte
+	_, _ = rand.Read(id[:])
+	c.msgID = binary.BigEndian.Uint16(id[:])
+	return c
+}
+
+// Publish updates the channel resources and notifies their observers.
+func (c *CoAPOutput) Publish(readings []sensor.Reading) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	updated := make(map[int]bool)
+	for _, r := range readings {
+		if _, ok := c.channels[r.Channel]; !ok {
+			continue
+		}
+		c.latest[r.Channel] = r
+		c.seq[r.Channel] = (c.seq[r.Channel] + 1) % observeSeqMax
+		updated[r.Channel] = true
+	}
+	for key, o := range c.observers {
+		if !updated[o.channel] {
+			continue
+		}
+		typ := typeNON
+		// while a confirmable notification is being retransmitted the next
+		// ones stay non-confirmable
+		if o.sent%conEvery == conEvery-1 && !o.pending {
+			typ = typeCON
+		}
+		m := c.notification(o, typ)
+		b := m.marshal()
+		if typ == typeCON {
+			c.confirm(key, o, m.id, b)
+		}
+		o.lastID = m.id
+		o.sent++
+		c.notify(b, o.addr)
+	}
+	return nil
+}
+
+// confirm retransmits the confirmable notification b until it is
+// acknowledged, doubling a randomized initial timeout each time (RFC 7252
+// section 4.2), and removes the observer when the last retransmission times
+// out. c.mu is held.
+func (c *CoAPOutput) confirm(key string, o *observer, id uint16, b []byte) {
+	o.pending, o.conID = true, id
+	timeout := c.ackTimeout + time.Duration(mathrand.Float64()*(ackRandomFactor-1)*float64(c.ackTimeout))
+	retransmits := 0
+	var expire func()
+	expire = func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+		if c.observers[key] != o || !o.pending || o.conID != id {
+			return
+		}
+		if retransmits == maxRetransmit {
+			delete(c.observers, key)
+			return
+		}
+		retransmits++
+		timeout *= 2
+		c.notify(b, o.addr)
+		o.timer = time.AfterFunc(timeout, expire)
+	}
+	o.timer = time.AfterFunc(timeout, expire)
+}
+
+func (c *CoAPOutput) notify(b []byte, addr net.Addr) {
+	if _, err := c.conn.WriteTo(b, addr); err != nil {
+		log.Printf("warning: coap notify %s: %v", addr, err)
+	}
+}
+
+// Close stops the server.
+func (c *CoAPOutput) Close() error {
+	c.mu.Lock()
+	select {
+	case <-c.done:
+		c.mu.Unlock()
+		return nil
+	default:
+		close(c.done)
+	}
+	c.mu.Unlock()
+	return c.conn.Close()
+}
+
+func (c *CoAPOutput) serve() {
+	buf := make([]byte, maxDatagram)
+	for {
+		n, addr, err := c.conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("warning: coap read: %v", err)
+			}
+			return
+		}
+		req, err := parse(buf[:n])
+		if err != nil {
+			continue
+		}
+		if resp, ok := c.handle(req, addr); ok {
+			if _, err := c.conn.WriteTo(resp.marshal(), addr); err != nil {
+				log.Printf("warning: coap reply %s: %v", addr, err)
+			}
+		}
+	}
+}
+
+// handle processes a message and returns the reply to send, if any.
+func (c *CoAPOutput) handle(req message, addr net.Addr) (message, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch req.typ {
+	case typeACK, typeRST:
+		c.acknowledge(req, addr)
+		return message{}, false
+	}
+	if req.code == codeEmpty {
+		// CoAP ping: reply with a reset
+		if req.typ == typeCON {
+			return message{typ: typeRST, id: req.id}, true
+		}
+		return message{}, false
+	}
+	resp := c.reply(req)
+	if req.code != codeGET {
+		resp.code = codeMethodNotAllowed
+		return resp, true
+	}
+	path := req.path()
+	if path == wellKnownCore {
+		if accept, ok := req.uintValue(optAccept); ok && accept != formatLinkFormat {
+			resp.code = codeNotAcceptable
+			return resp, true
+		}
+		resp.code = codeContent
+		resp.options = append(resp.options, uintOption(optContentFormat, formatLinkFormat))
+		resp.payload = []byte(c.links(req.queries()))
+		return resp, true
+	}
+	ch, ok := c.channelOf(path)
+	if !ok {
+		resp.code = codeNotFound
+		return resp, true
+	}
+	format := c.format
+	if accept, ok := req.uintValue(optAccept); ok {
+		if accept != formatCBOR && accept != formatJSON {
+			resp.code = codeNotAcceptable
+			return resp, true
+		}
+		format = int(accept)
+	}
+	key := observerKey(addr, req.token)
+	if obs, ok := req.uintValue(optObserve); ok {
+		switch {
+		case obs == 1:
+			delete(c.observers, key)
+		case obs == 0 && (c.observers[key] != nil || len(c.observers) < c.max):
+			c.observers[key] = &observer{addr: addr, token: req.token, channel: ch, format: format}
+			resp.options = append(resp.options, uintOption(optObserve, c.seq[ch]))
+		}
+	} else {
+		delete(c.observers, key)
+	}
+	resp.code = codeContent
+	resp.options = append(resp.options, uintOption(optContentFormat, uint32(format)))
+	resp.payload = c.encode(ch, format)
+	return resp, true
+}
+
+// reply returns the response skeleton: a piggybacked acknowledgement for a
+// confirmable request, a non-confirmable message otherwise.
+func (c *CoAPOutput) reply(req message) message {
+	if req.typ == typeCON {
+		return message{typ: typeACK, id: req.id, token: req.token}
+	}
+	return message{typ: typeNON, id: c.nextID(), token: req.token}
+}
+
+// acknowledge handles an ACK or RST from an observer: a reset cancels the
+// observation (RFC 7641 section 3.6).
+func (c *CoAPOutput) acknowledge(m message, addr net.Addr) {
+	for key, o := range c.observers {
+		if o.addr.String() != addr.String() {
+			continue
+		}
+		if m.typ == typeACK && o.pending && o.conID == m.id {
+			o.pending = false
+			o.timer.Stop()
+		}
+		if m.typ == typeRST && (o.pending && o.conID == m.id || o.lastID == m.id) {
+			delete(c.observers, key)
+		}
+	}
+}
+
+func (c *CoAPOutput) notification(o *observer, typ int) message {
+	return message{
+		typ:     typ,
+		code:    codeContent,
+		id:      c.nextID(),
+		token:   o.token,
+		options: []option{uintOption(optObserve, c.seq[o.channel]), uintOption(optContentFormat, uint32(o.format))},
+		payload: c.encode(o.channel, o.format),
+	}
+}
+
+func (c *CoAPOutput) nextID() uint16 {
+	c.msgID++
+	return c.msgID
+}
+
+// channelOf returns the channel of a resource path.
+func (c *CoAPOutput) channelOf(path string) (int, bool) {
+	rest, ok := strings.CutPrefix(path, c.prefix+"/")
+	if !ok {
+		return 0, false
+	}
+	ch, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, false
+	}
+	_, ok = c.channels[ch]
+	return ch, ok
+}
+
+// links returns the CoRE link format listing of the channel resources,
+// filtered by rt= or title= queries.
+func (c *CoAPOutput) links(queries []string) string {
+	var ids []int
+	for ch := range c.channels {
+		ids = append(ids, ch)
+	}
+	sort.Ints(ids)
+	var links []string
+	for _, id := range ids {
+		ch := c.channels[id]
+		if !matches(queries, ch) {
+			continue
+		}
+		l := fmt.Sprintf(`<%s/%d>;rt="%s";if="core.s";ct="%d %d";obs`, c.prefix, id, resourceType, formatCBOR, formatJSON)
+		if ch.Name != "" {
+			l += fmt.Sprintf(";title=%q", ch.Name)
+		}
+		links = append(links, l)
+	}
+	return strings.Join(links, ",")
+}
+
+func matches(queries []string, ch config.ChannelConfig) bool {
+	for _, q := range queries {
+		k, v, _ := strings.Cut(q, "=")
+		switch k {
+		case "rt":
+			if v != resourceType {
+				return false
+			}
+		case "title":
+			if v != ch.Name {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// encode returns the representation of a channel in a content format.
+func (c *CoAPOutput) encode(ch, format int) []byte {
+	r, ok := c.latest[ch]
+	if !ok {
+		r = sensor.Reading{Channel: ch}
+	}
+	rd := c.channels.Reading(r)
+	rep := representation{Channel: rd.Channel, Name: rd.Name, Unit: rd.Unit}
+	if ok {
+		rep.Value, rep.Raw, rep.Timestamp = &rd.Value, &rd.Raw, &rd.Timestamp
+	}
+	if format == formatJSON {
+		b, _ := json.Marshal(rep)
+		return b
+	}
+	return rep.cbor()
+}
+
+// cbor encodes the representation as a CBOR map with the JSON keys; the
+// timestamp is an epoch-based date/time (tag 1) in fractional seconds.
+func (r representation) cbor() []byte {
+	n := 2
+	for _, set := range []bool{r.Name != "", r.Value != nil, r.Raw != nil, r.Timestamp != nil} {
+		if set {
+			n++
+		}
+	}
+	b := cborHead(nil, majorMap, uint64(n))
+	b = cborInt(cborText(b, "channel"), int64(r.Channel))
+	if r.Name != "" {
+		b = cborText(cborText(b, "name"), r.Name)
+	}
+	b = cborText(cborText(b, "unit"), r.Unit)
+	if r.Value != nil {
+		b = cborFloat(cborText(b, "value"), *r.Value)
+	}
+	if r.Raw != nil {
+		b = cborInt(cborText(b, "raw"), int64(*r.Raw))
+	}
+	if r.Timestamp != nil {
+		b = cborHead(cborText(b, "timestamp"), majorTag, tagEpoch)
+		b = cborFloat(b, float64(r.Timestamp.UnixMilli())/1000)
+	}
+	return b
+}
+
+func observerKey(addr net.Addr, token []byte) string {
+	return addr.String() + "/" + hex.EncodeToString(token)
+}
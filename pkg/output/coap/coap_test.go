@@ -0,0 +1,201 @@
+package coap
+
+import (
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+var testReadings = []sensor.Reading{{Channel: 0, Raw: -12, Value: 3.5, Timestamp: time.Date(2025, 9, 19, 14, 41, 54, 500e6, time.UTC)}}
+
+func TestMessageRoundTrip(t *testing.T) {
+	m := message{typ: typeCON, code: codeGET, id: 0x1234, token: []byte{1, 2},
+		options: []option{{number: optURIPath, value: []byte("channels")}, uintOption(optObserve, 0), {number: optURIPath, value: []byte("0")}, uintOption(optAccept, 300)},
+		payload: []byte("x")}
+	got, err := parse(m.marshal())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got.path() != "/channels/0" || got.id != 0x1234 || string(got.token) != "\x01\x02" || string(got.payload) != "x" {
+		t.Fatalf("unexpected message %+v", got)
+	}
+	if v, ok := got.uintValue(optAccept); !ok || v != 300 {
+		t.Fatalf("accept = %d, %v", v, ok)
+	}
+	if _, err := parse([]byte{0x48, 1, 0, 0}); err == nil {
+		t.Fatal("expected an error for a truncated token")
+	}
+}
+
+func TestCBOR(t *testing.T) {
+	v, raw, ts := 3.5, int16(-12), testReadings[0].Timestamp
+	b := representation{Channel: 0, Unit: "V", Value: &v, Raw: &raw, Timestamp: &ts}.cbor()
+	// {"channel": 0, "unit": "V", "value": 3.5, "raw": -12, "timestamp": 1(1758292914.5)}
+	want := "a5" + "676368616e6e656c" + "00" + "64756e6974" + "6156" +
+		"6576616c7565" + "fb400c000000000000" + "63726177" + "2b" +
+		"6974696d657374616d70" + "c1" + "fb41da335aeca00000"
+	if got := hex.EncodeToString(b); got != want {
+		t.Fatalf("cbor = %s, want %s", got, want)
+	}
+}
+
+type client struct {
+	t    *testing.T
+	conn net.Conn
+}
+
+func (c client) exchange(m message) message {
+	c.t.Helper()
+	if _, err := c.conn.Write(m.marshal()); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+	return c.receive()
+}
+
+func (c client) receive() message {
+	c.t.Helper()
+	buf := make([]byte, maxDatagram)
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := c.conn.Read(buf)
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+	m, err := parse(buf[:n])
+	if err != nil {
+		c.t.Fatalf("parse: %v", err)
+	}
+	return m
+}
+
+func get(id uint16, token string, path ...string) message {
+	m := message{typ: typeCON, code: codeGET, id: id, token: []byte(token)}
+	for _, p := range path {
+		m.options = append(m.options, option{number: optURIPath, value: []byte(p)})
+	}
+	return m
+}
+
+func TestServer(t *testing.T) {
+	channels := []config.ChannelConfig{{Channel: 0, Name: "battery"}, {Channel: 1}}
+	o, err := NewCoAP(Config{Address: "127.0.0.1:0", ContentFormat: ContentFormatJSON}, channels)
+	if err != nil {
+		t.Fatalf("NewCoAP: %v", err)
+	}
+	defer o.Close()
+	conn, err := net.Dial("udp", o.(*CoAPOutput).conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	c := client{t: t, conn: conn}
+
+	resp := c.exchange(get(1, "", ".well-known", "core"))
+	want := `</channels/0>;rt="ads1115.channel";if="core.s";ct="60 50";obs;title="battery",</channels/1>;rt="ads1115.channel";if="core.s";ct="60 50";obs`
+	if resp.typ != typeACK || resp.id != 1 || resp.code != codeContent || string(resp.payload) != want {
+		t.Fatalf("well-known core = %+v %q", resp, resp.payload)
+	}
+	if resp := c.exchange(get(2, "", "channels", "7")); resp.code != codeNotFound {
+		t.Fatalf("unknown resource code = %x", resp.code)
+	}
+
+	// observe channel 0 before any reading
+	req := get(3, "ob", "channels", "0")
+	req.options = append(req.options, uintOption(optObserve, 0))
+	resp = c.exchange(req)
+	if _, ok := resp.uintValue(optObserve); !ok || string(resp.payload) != `{"channel":0,"name":"battery","unit":"V"}` {
+		t.Fatalf("observe response = %+v %q", resp, resp.payload)
+	}
+	_ = o.Publish(testReadings)
+	n := c.receive()
+	if seq, _ := n.uintValue(optObserve); n.typ != typeNON || string(n.token) != "ob" || seq != 1 ||
+		string(n.payload) != `{"channel":0,"name":"battery","unit":"V","value":3.5,"raw":-12,"timestamp":"2025-09-19T14:41:54.5Z"}` {
+		t.Fatalf("notification = %+v %q", n, n.payload)
+	}
+
+	// a reset cancels the observation
+	_, _ = conn.Write(message{typ: typeRST, id: n.id}.marshal())
+	time.Sleep(50 * time.Millisecond)
+	srv := o.(*CoAPOutput)
+	srv.mu.Lock()
+	observers := len(srv.observers)
+	srv.mu.Unlock()
+	if observers != 0 {
+		t.Fatalf("%d observers after reset, want 0", observers)
+	}
+
+	// CBOR requested with Accept
+	req = get(4, "", "channels", "0")
+	req.options = append(req.options, uintOption(optAccept, formatCBOR))
+	resp = c.exchange(req)
+	if f, _ := resp.uintValue(optContentFormat); f != formatCBOR || resp.payload[0] != 0xa6 {
+		t.Fatalf("cbor response = %+v %x", resp, resp.payload)
+	}
+}
+
+func TestConfirmableRetransmission(t *testing.T) {
+	o, err := NewCoAP(Config{Address: "127.0.0.1:0"}, []config.ChannelConfig{{Channel: 0}})
+	if err != nil {
+		t.Fatalf("NewCoAP: %v", err)
+	}
+	defer o.Close()
+	srv := o.(*CoAPOutput)
+	conn, err := net.Dial("udp", srv.conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	c := client{t: t, conn: conn}
+	observers := func() int {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		return len(srv.observers)
+	}
+	// the next notification of an observer is confirmable
+	observe := func(token string) {
+		req := get(1, token, "channels", "0")
+		req.options = append(req.options, uintOption(optObserve, 0))
+		c.exchange(req)
+		srv.mu.Lock()
+		srv.ackTimeout = 10 * time.Millisecond
+		for _, ob := range srv.observers {
+			ob.sent = conEvery - 1
+		}
+		srv.mu.Unlock()
+	}
+
+	// an acknowledged notification is not retransmitted
+	observe("a")
+	_ = o.Publish(testReadings)
+	n := c.receive()
+	if n.typ != typeCON {
+		t.Fatalf("notification type = %d, want CON", n.typ)
+	}
+	_, _ = conn.Write(message{typ: typeACK, id: n.id}.marshal())
+	time.Sleep(100 * time.Millisecond)
+	_ = conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if _, err := conn.Read(make([]byte, maxDatagram)); err == nil || observers() != 1 {
+		t.Fatalf("acknowledged notification retransmitted (observers %d)", observers())
+	}
+	_, _ = conn.Write(message{typ: typeRST, id: n.id}.marshal())
+
+	// an unacknowledged one is sent maxRetransmit more times, then the observer is removed
+	observe("b")
+	_ = o.Publish(testReadings)
+	first := c.receive()
+	for i := 0; i < maxRetransmit; i++ {
+		if m := c.receive(); m.typ != typeCON || m.id != first.id || string(m.payload) != string(first.payload) {
+			t.Fatalf("retransmission %d = %+v, want %+v", i+1, m, first)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for observers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("observer kept after the retransmissions")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
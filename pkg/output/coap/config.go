@@ -0,0 +1,52 @@
+package coap
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// CoAP content formats of the coap output.
+const (
+	ContentFormatCBOR = "cbor"
+	ContentFormatJSON = "json"
+)
+
+// Config holds the settings of the CoAP server output.
+type Config struct {
+	// Address is the UDP listen address. Default: ":5683".
+	Address string `json:"address,omitempty"`
+	// PathPrefix is the path of the channel resources (<prefix>/<channel>).
+	// Default: "channels".
+	PathPrefix string `json:"path_prefix,omitempty"`
+	// ContentFormat is the format served when the request has no Accept
+	// option: "cbor" (default) or "json".
+	ContentFormat string `json:"content_format,omitempty"`
+	// MaxObservers limits the registered observations. Default: 64.
+	MaxObservers int `json:"max_observers,omitempty"`
+}
+
+// Validate checks the address, path prefix, content format and limits.
+func (c Config) Validate() error {
+	if c.Address != "" {
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			return fmt.Errorf("invalid address %q: %w", c.Address, err)
+		}
+	}
+	if c.PathPrefix != "" {
+		for _, seg := range strings.Split(c.PathPrefix, "/") {
+			if seg == "" || seg == ".well-known" {
+				return fmt.Errorf("invalid path_prefix %q", c.PathPrefix)
+			}
+		}
+	}
+	switch c.ContentFormat {
+	case "", ContentFormatCBOR, ContentFormatJSON:
+	default:
+		return fmt.Errorf("invalid content_format %q: use %s or %s", c.ContentFormat, ContentFormatCBOR, ContentFormatJSON)
+	}
+	if c.MaxObservers < 0 {
+		return fmt.Errorf("invalid max_observers %d", c.MaxObservers)
+	}
+	return nil
+}
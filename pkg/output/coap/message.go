@@ -0,0 +1,207 @@
+package coap
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// Message types.
+const (
+	typeCON = 0
+	typeNON = 1
+	typeACK = 2
+	typeRST = 3
+)
+
+// Codes (class << 5 | detail).
+const (
+	codeEmpty            = 0x00
+	codeGET              = 0x01
+	codeContent          = 0x45 // 2.05
+	codeBadRequest       = 0x80 // 4.00
+	codeNotFound         = 0x84 // 4.04
+	codeMethodNotAllowed = 0x85 // 4.05
+	codeNotAcceptable    = 0x86 // 4.06
+)
+
+// Option numbers.
+const (
+	optObserve       = 6
+	optURIPath       = 11
+	optContentFormat = 12
+	optURIQuery      = 15
+	optAccept        = 17
+)
+
+// Content formats.
+const (
+	formatLinkFormat = 40
+	formatJSON       = 50
+	formatCBOR       = 60
+)
+
+var errMalformed = errors.New("malformed coap message")
+
+type option struct {
+	number int
+	value  []byte
+}
+
+// message is a CoAP message (RFC 7252 section 3).
+type message struct {
+	typ     int
+	code    byte
+	id      uint16
+	token   []byte
+	options []option
+	payload []byte
+}
+
+// parse decodes a datagram.
+func parse(b []byte) (message, error) {
+	var m message
+	if len(b) < 4 || b[0]>>6 != 1 {
+		return m, errMalformed
+	}
+	m.typ = int(b[0]>>4) & 0x3
+	tkl := int(b[0] & 0xf)
+	m.code = b[1]
+	m.id = binary.BigEndian.Uint16(b[2:4])
+	if tkl > 8 || len(b) < 4+tkl {
+		return m, errMalformed
+	}
+	m.token = append([]byte(nil), b[4:4+tkl]...)
+	b = b[4+tkl:]
+	number := 0
+	for len(b) > 0 {
+		if b[0] == 0xff {
+			if len(b) == 1 {
+				return m, errMalformed
+			}
+			m.payload = append([]byte(nil), b[1:]...)
+			break
+		}
+		delta, length := int(b[0]>>4), int(b[0]&0xf)
+		b = b[1:]
+		var err error
+		if delta, b, err = extended(delta, b); err != nil {
+			return m, err
+		}
+		if length, b, err = extended(length, b); err != nil {
+			return m, err
+		}
+		if len(b) < length {
+			return m, errMalformed
+		}
+		number += delta
+		m.options = append(m.options, option{number: number, value: append([]byte(nil), b[:length]...)})
+		b = b[length:]
+	}
+	return m, nil
+}
+
+// extended decodes the extended option delta or length of nibble v.
+func extended(v int, b []byte) (int, []byte, error) {
+	switch v {
+	case 13:
+		if len(b) < 1 {
+			return 0, nil, errMalformed
+		}
+		return int(b[0]) + 13, b[1:], nil
+	case 14:
+		if len(b) < 2 {
+			return 0, nil, errMalformed
+		}
+		return int(binary.BigEndian.Uint16(b)) + 269, b[2:], nil
+	case 15:
+		return 0, nil, errMalformed
+	}
+	return v, b, nil
+}
+
+// marshal encodes the message; options are sorted by number.
+func (m message) marshal() []byte {
+	b := []byte{1<<6 | byte(m.typ)<<4 | byte(len(m.token)), m.code, byte(m.id >> 8), byte(m.id)}
+	b = append(b, m.token...)
+	opts := append([]option(nil), m.options...)
+	sort.SliceStable(opts, func(i, j int) bool { return opts[i].number < opts[j].number })
+	prev := 0
+	for _, o := range opts {
+		delta, length := o.number-prev, len(o.value)
+		prev = o.number
+		dn, dx := nibble(delta)
+		ln, lx := nibble(length)
+		b = append(b, dn<<4|ln)
+		b = append(b, dx...)
+		b = append(b, lx...)
+		b = append(b, o.value...)
+	}
+	if len(m.payload) > 0 {
+		b = append(b, 0xff)
+		b = append(b, m.payload...)
+	}
+	return b
+}
+
+// nibble returns the 4-bit encoding of an option delta or length and its
+// extended bytes.
+func nibble(v int) (byte, []byte) {
+	switch {
+	case v < 13:
+		return byte(v), nil
+	case v < 269:
+		return 13, []byte{byte(v - 13)}
+	default:
+		return 14, binary.BigEndian.AppendUint16(nil, uint16(v-269))
+	}
+}
+
+// uintOption encodes v in the minimal number of bytes (0 is empty).
+func uintOption(number int, v uint32) option {
+	var b []byte
+	for v > 0 {
+		b = append([]byte{byte(v)}, b...)
+		v >>= 8
+	}
+	return option{number: number, value: b}
+}
+
+// uintValue decodes an option uint and reports whether the option is present.
+func (m message) uintValue(number int) (uint32, bool) {
+	for _, o := range m.options {
+		if o.number == number {
+			var v uint32
+			for _, c := range o.value {
+				v = v<<8 | uint32(c)
+			}
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// path returns the Uri-Path segments joined with "/".
+func (m message) path() string {
+	var p string
+	for _, o := range m.options {
+		if o.number == optURIPath {
+			p += "/" + string(o.value)
+		}
+	}
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+// queries returns the Uri-Query options.
+func (m message) queries() []string {
+	var q []string
+	for _, o := range m.options {
+		if o.number == optURIQuery {
+			q = append(q, string(o.value))
+		}
+	}
+	return q
+}
@@ -10,6 +10,12 @@ import (
 
 type ConsoleOutput struct{}
 
+func init() {
+	output.Register("console", output.Registration{
+		New: func(any, output.Env) (output.Output, error) { return NewConsole(), nil },
+	})
+}
+
 func NewConsole() output.Output { return &ConsoleOutput{} }
 
 func (c *ConsoleOutput) Publish(readings []sensor.Reading) error {
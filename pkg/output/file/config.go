@@ -0,0 +1,58 @@
+package file
+
+import (
+	"fmt"
+)
+
+// File output formats.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// File output fsync policies.
+const (
+	FsyncNever  = "never"
+	FsyncRotate = "rotate"
+	FsyncAlways = "always"
+)
+
+// Config holds the settings of the file output.
+type Config struct {
+	// Path is the file being written; rotated files are renamed next to it
+	// with a timestamp suffix (readings.csv -> readings-20250919T144154Z.csv).
+	Path string `json:"path"`
+	// Format is "csv" (one row per snapshot, default) or "jsonl" (one JSON object per reading).
+	Format string `json:"format,omitempty"`
+	// MaxSizeBytes rotates the file when it reaches this size. 0 disables it.
+	MaxSizeBytes int64 `json:"max_size_bytes,omitempty"`
+	// RotateInterval rotates the file after this many seconds. 0 disables it.
+	RotateInterval int `json:"rotate_interval,omitempty"`
+	// Compress gzips rotated files.
+	Compress bool `json:"compress,omitempty"`
+	// MaxFiles is the number of rotated files kept. 0 keeps all of them.
+	MaxFiles int `json:"max_files,omitempty"`
+	// Fsync is "never", "rotate" (on rotation and close, default) or "always" (after every snapshot).
+	Fsync string `json:"fsync,omitempty"`
+}
+
+// Validate checks the path, format, rotation and fsync settings.
+func (c Config) Validate() error {
+	if c.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	switch c.Format {
+	case "", FormatCSV, FormatJSONL:
+	default:
+		return fmt.Errorf("invalid format %q: use %s or %s", c.Format, FormatCSV, FormatJSONL)
+	}
+	switch c.Fsync {
+	case "", FsyncNever, FsyncRotate, FsyncAlways:
+	default:
+		return fmt.Errorf("invalid fsync %q: use %s, %s or %s", c.Fsync, FsyncNever, FsyncRotate, FsyncAlways)
+	}
+	if c.MaxSizeBytes < 0 || c.RotateInterval < 0 || c.MaxFiles < 0 {
+		return fmt.Errorf("max_size_bytes, rotate_interval and max_files must not be negative")
+	}
+	return nil
+}
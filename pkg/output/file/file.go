@@ -0,0 +1,371 @@
+// Package file implements an output writing snapshots to a local CSV or JSON
+// Lines file, rotated by size or age with optional gzip compression and a
+// retention count.
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	// rotatedTimeFormat is the timestamp suffix of rotated files; it sorts chronologically.
+	rotatedTimeFormat = "20060102T150405.000Z"
+	gzipExt           = ".gz"
+)
+
+type FileOutput struct {
+	cfg      Config
+	columns  []config.ChannelConfig // CSV columns: the enabled channels
+	channels output.Channels
+	now      func() time.Time
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	opened time.Time
+	closed bool
+}
+
+func init() {
+	output.Register("file", output.Registration{
+		Options:  func() any { return &Config{} },
+		Required: true,
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewFile(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+// NewFile opens (or appends to) the configured file.
+func NewFile(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	return newFile(cfg, channels, time.Now)
+}
+
+func newFile(cfg Config, channels []config.ChannelConfig, now func() time.Time) (*FileOutput, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Format == "" {
+		cfg.Format = FormatCSV
+	}
+	if cfg.Fsync == "" {
+		cfg.Fsync = FsyncRotate
+	}
+	o := &FileOutput{cfg: cfg, channels: output.NewChannels(channels), now: now}
+	for _, ch := range channels {
+		if ch.Enabled {
+			o.columns = append(o.columns, ch)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("file output: %w", err)
+	}
+	if err := o.open(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *FileOutput) Publish(readings []sensor.Reading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return fmt.Errorf("file output: closed")
+	}
+	if o.f == nil {
+		// a previous rotation could not reopen the file
+		if err := o.open(); err != nil {
+			return err
+		}
+	}
+	if o.shouldRotate() {
+		if err := o.rotate(); err != nil {
+			if o.f == nil {
+				return err
+			}
+			// the file was reopened unrotated: keep appending to it
+			log.Printf("warning: %v", err)
+		}
+	}
+	b, err := o.encode(readings)
+	if err != nil {
+		return err
+	}
+	n, err := o.f.Write(b)
+	o.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("file output: %w", err)
+	}
+	if o.cfg.Fsync == FsyncAlways {
+		return o.f.Sync()
+	}
+	return nil
+}
+
+// Close syncs (unless fsync is "never") and closes the file.
+func (o *FileOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	return o.close()
+}
+
+// open opens the file for appending, writing the CSV header to a new file.
+// An existing CSV file with another header is archived first, since its
+// columns do not match the rows that would be appended.
+func (o *FileOutput) open() error {
+	if o.cfg.Format == FormatCSV {
+		if err := o.checkHeader(); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(o.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("file output: %w", err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("file output: %w", err)
+	}
+	o.f, o.size, o.opened = f, st.Size(), o.now()
+	if o.size == 0 && o.cfg.Format == FormatCSV {
+		b, err := o.csvLine(o.header())
+		if err != nil {
+			return err
+		}
+		n, err := o.f.Write(b)
+		o.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("file output: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkHeader archives the file when its first line is not the current header.
+func (o *FileOutput) checkHeader() error {
+	f, err := os.Open(o.cfg.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("file output: %w", err)
+	}
+	line, err := bufio.NewReader(f).ReadString('\n')
+	f.Close()
+	if line == "" && err == io.EOF {
+		return nil
+	}
+	want, herr := o.csvLine(o.header())
+	if herr != nil {
+		return herr
+	}
+	if line == string(want) {
+		return nil
+	}
+	log.Printf("file output: %s has other columns, rotating it", o.cfg.Path)
+	return o.archive()
+}
+
+func (o *FileOutput) close() error {
+	if o.f == nil {
+		return nil
+	}
+	var err error
+	if o.cfg.Fsync != FsyncNever {
+		err = o.f.Sync()
+	}
+	if cerr := o.f.Close(); err == nil {
+		err = cerr
+	}
+	o.f = nil
+	return err
+}
+
+func (o *FileOutput) shouldRotate() bool {
+	if o.cfg.MaxSizeBytes > 0 && o.size >= o.cfg.MaxSizeBytes {
+		return true
+	}
+	return o.cfg.RotateInterval > 0 && o.now().Sub(o.opened) >= time.Duration(o.cfg.RotateInterval)*time.Second
+}
+
+// rotate closes the current file, archives it and opens a new file. When the
+// rename fails the current file is reopened; o.f is nil after any other
+// failure and Publish opens the file again.
+func (o *FileOutput) rotate() error {
+	if err := o.close(); err != nil {
+		return fmt.Errorf("file output: %w", err)
+	}
+	if err := o.archive(); err != nil {
+		if oerr := o.open(); oerr != nil {
+			return fmt.Errorf("%w (reopen: %v)", err, oerr)
+		}
+		return err
+	}
+	return o.open()
+}
+
+// archive renames (and optionally compresses) the closed file and removes
+// the rotated files beyond max_files.
+func (o *FileOutput) archive() error {
+	ext := filepath.Ext(o.cfg.Path)
+	rotated := strings.TrimSuffix(o.cfg.Path, ext) + "-" + o.now().UTC().Format(rotatedTimeFormat) + ext
+	if err := os.Rename(o.cfg.Path, rotated); err != nil {
+		return fmt.Errorf("file output: rotate: %w", err)
+	}
+	if o.cfg.Compress {
+		if err := compress(rotated, o.cfg.Fsync != FsyncNever); err != nil {
+			// keep the uncompressed file rather than losing data
+			log.Printf("warning: file output: compress %s: %v", rotated, err)
+		}
+	}
+	if o.cfg.MaxFiles > 0 {
+		if err := o.prune(); err != nil {
+			log.Printf("warning: file output: prune: %v", err)
+		}
+	}
+	return nil
+}
+
+// prune removes the oldest rotated files so at most max_files remain. Only
+// the names archive gives are considered, so the files of another output
+// sharing the prefix (readings-raw.csv next to readings.csv) are kept.
+func (o *FileOutput) prune() error {
+	dir, base := filepath.Split(o.cfg.Path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return err
+	}
+	var rotated []string
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(strings.TrimSuffix(e.Name(), gzipExt), prefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, ext)
+		if _, err := time.Parse(rotatedTimeFormat, stamp); !ok || err != nil {
+			continue
+		}
+		rotated = append(rotated, filepath.Join(dir, e.Name()))
+	}
+	if len(rotated) <= o.cfg.MaxFiles {
+		return nil
+	}
+	sort.Strings(rotated)
+	for _, p := range rotated[:len(rotated)-o.cfg.MaxFiles] {
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compress replaces path with path.gz.
+func compress(path string, sync bool) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(path+gzipExt, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && sync {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path + gzipExt)
+		return err
+	}
+	return os.Remove(path)
+}
+
+// encode renders a snapshot: one CSV row or one JSON line per reading.
+func (o *FileOutput) encode(readings []sensor.Reading) ([]byte, error) {
+	if o.cfg.Format == FormatJSONL {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		for _, r := range readings {
+			if err := enc.Encode(o.channels.Reading(r)); err != nil {
+				return nil, err
+			}
+		}
+		return buf.Bytes(), nil
+	}
+	byChannel := make(map[int]sensor.Reading, len(readings))
+	var ts time.Time
+	for _, r := range readings {
+		byChannel[r.Channel] = r
+		if r.Timestamp.After(ts) {
+			ts = r.Timestamp
+		}
+	}
+	row := []string{ts.Format(time.RFC3339Nano)}
+	for _, ch := range o.columns {
+		r, ok := byChannel[ch.Channel]
+		if !ok {
+			row = append(row, "", "")
+			continue
+		}
+		row = append(row, strconv.FormatFloat(r.Value, 'f', -1, 64), strconv.Itoa(int(r.Raw)))
+	}
+	return o.csvLine(row)
+}
+
+// header returns the CSV header: timestamp, then value and raw columns per channel.
+func (o *FileOutput) header() []string {
+	h := []string{"timestamp"}
+	for _, ch := range o.columns {
+		name := ch.Name
+		if name == "" {
+			name = fmt.Sprintf("ch%d", ch.Channel)
+		}
+		h = append(h, name, name+"_raw")
+	}
+	return h
+}
+
+func (o *FileOutput) csvLine(fields []string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(fields); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,193 @@
+package file
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+var testTS = time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)
+
+// clock is a manually advanced time source.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(b)
+}
+
+func TestCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "readings.csv")
+	channels := []config.ChannelConfig{{Channel: 0, Enabled: true, Name: "battery"}, {Channel: 1, Enabled: false}, {Channel: 2, Enabled: true}}
+	o, err := newFile(Config{Path: path}, channels, time.Now)
+	if err != nil {
+		t.Fatalf("newFile: %v", err)
+	}
+	_ = o.Publish([]sensor.Reading{{Channel: 0, Raw: 19023, Value: 3.72, Timestamp: testTS}, {Channel: 2, Raw: -12, Value: -0.0015, Timestamp: testTS}})
+	_ = o.Publish([]sensor.Reading{{Channel: 2, Raw: 1, Value: 0.5, Timestamp: testTS.Add(time.Second)}})
+	if err := o.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	want := "timestamp,battery,battery_raw,ch2,ch2_raw\n" +
+		"2025-09-19T14:41:54Z,3.72,19023,-0.0015,-12\n" +
+		"2025-09-19T14:41:55Z,,,0.5,1\n"
+	if got := readFile(t, path); got != want {
+		t.Fatalf("csv mismatch:\n got: %q\nwant: %q", got, want)
+	}
+
+	// reopening appends without a second header
+	o, err = newFile(Config{Path: path}, channels, time.Now)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	_ = o.Publish([]sensor.Reading{{Channel: 0, Raw: 1, Value: 1, Timestamp: testTS}})
+	_ = o.Close()
+	if got := readFile(t, path); strings.Count(got, "timestamp") != 1 || !strings.HasSuffix(got, "2025-09-19T14:41:54Z,1,1,,\n") {
+		t.Fatalf("unexpected content after reopen: %q", got)
+	}
+
+	// other columns start a new file, the old one is rotated
+	channels[1].Enabled = true
+	clk := &clock{t: testTS}
+	o, err = newFile(Config{Path: path}, channels, clk.now)
+	if err != nil {
+		t.Fatalf("reopen with other channels: %v", err)
+	}
+	_ = o.Close()
+	if got := readFile(t, path); got != "timestamp,battery,battery_raw,ch1,ch1_raw,ch2,ch2_raw\n" {
+		t.Fatalf("unexpected content after column change: %q", got)
+	}
+	if got := readFile(t, filepath.Join(filepath.Dir(path), "readings-20250919T144154.000Z.csv")); !strings.HasPrefix(got, want) {
+		t.Fatalf("unexpected rotated content %q", got)
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "readings.jsonl")
+	o, err := newFile(Config{Path: path, Format: FormatJSONL}, []config.ChannelConfig{{Channel: 1, Unit: "A"}}, time.Now)
+	if err != nil {
+		t.Fatalf("newFile: %v", err)
+	}
+	_ = o.Publish([]sensor.Reading{{Channel: 1, Raw: -12, Value: -0.0015, Timestamp: testTS}})
+	_ = o.Close()
+	want := `{"channel":1,"unit":"A","value":-0.0015,"raw":-12,"timestamp":"2025-09-19T14:41:54Z"}` + "\n"
+	if got := readFile(t, path); got != want {
+		t.Fatalf("jsonl mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "readings.csv")
+	clk := &clock{t: testTS}
+	// files of another output sharing the prefix are never pruned
+	foreign := []string{"readings-raw.csv", "readings-raw-20250101T000000.000Z.csv.gz"}
+	for _, name := range foreign {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := Config{Path: path, MaxSizeBytes: 60, RotateInterval: 3600, Compress: true, MaxFiles: 2}
+	o, err := newFile(cfg, []config.ChannelConfig{{Channel: 0, Enabled: true}}, clk.now)
+	if err != nil {
+		t.Fatalf("newFile: %v", err)
+	}
+	r := []sensor.Reading{{Channel: 0, Raw: 19023, Value: 3.72, Timestamp: testTS}}
+	// header (22 bytes) + 2 rows (32 bytes each) exceed 60 bytes: the third publish rotates
+	for i := 0; i < 3; i++ {
+		clk.t = clk.t.Add(time.Second)
+		_ = o.Publish(r)
+	}
+	// age-based rotation
+	clk.t = clk.t.Add(time.Hour)
+	_ = o.Publish(r)
+	// the second publish after that rotates again and prunes the oldest file
+	for i := 0; i < 2; i++ {
+		clk.t = clk.t.Add(time.Second)
+		_ = o.Publish(r)
+	}
+	if err := o.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	for _, name := range foreign {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("foreign file pruned: %v", err)
+		}
+	}
+	rotated, _ := filepath.Glob(filepath.Join(dir, "readings-2*"))
+	sort.Strings(rotated)
+	want := []string{"readings-20250919T154157.000Z.csv.gz", "readings-20250919T154159.000Z.csv.gz"}
+	if len(rotated) != len(want) {
+		t.Fatalf("rotated files = %v, want %v", rotated, want)
+	}
+	for i := range want {
+		if filepath.Base(rotated[i]) != want[i] {
+			t.Fatalf("rotated files = %v, want %v", rotated, want)
+		}
+	}
+	f, err := os.Open(rotated[0])
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	b, _ := io.ReadAll(zr)
+	if !strings.HasPrefix(string(b), "timestamp,ch0,ch0_raw\n") || strings.Count(string(b), "\n") != 2 {
+		t.Fatalf("unexpected rotated content %q", b)
+	}
+	if got := readFile(t, path); strings.Count(got, "\n") != 2 {
+		t.Fatalf("unexpected current file %q", got)
+	}
+}
+
+func TestRotationFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "readings.csv")
+	clk := &clock{t: testTS}
+	o, err := newFile(Config{Path: path, RotateInterval: 60}, []config.ChannelConfig{{Channel: 0, Enabled: true}}, clk.now)
+	if err != nil {
+		t.Fatalf("newFile: %v", err)
+	}
+	// a non-empty directory under the rotated name makes the rename fail
+	clk.t = clk.t.Add(time.Minute)
+	blocker := filepath.Join(dir, "readings-"+clk.t.UTC().Format(rotatedTimeFormat)+".csv")
+	if err := os.MkdirAll(filepath.Join(blocker, "x"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	r := []sensor.Reading{{Channel: 0, Raw: 1, Value: 1, Timestamp: testTS}}
+	if err := o.Publish(r); err != nil {
+		t.Fatalf("publish after a failed rename: %v", err)
+	}
+	// a rotation that could not reopen the file is retried by the next publish
+	_ = o.close()
+	if err := o.Publish(r); err != nil {
+		t.Fatalf("publish after a failed reopen: %v", err)
+	}
+	if err := o.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := readFile(t, path); got != "timestamp,ch0,ch0_raw\n2025-09-19T14:41:54Z,1,1\n2025-09-19T14:41:54Z,1,1\n" {
+		t.Fatalf("unexpected current file %q", got)
+	}
+	if err := o.Publish(r); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Fatalf("publish after Close = %v", err)
+	}
+}
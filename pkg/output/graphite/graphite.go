@@ -0,0 +1,78 @@
+// Package graphite implements an output sending channel values to Graphite
+// (carbon) using the plaintext protocol.
+package graphite
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output/netsink"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+// DefaultProtocol is the Graphite transport used when none is configured.
+const DefaultProtocol = "tcp"
+
+type GraphiteOutput struct {
+	sink     *netsink.Sink
+	path     netsink.MetricPath
+	raw      bool
+	channels output.Channels
+}
+
+func init() {
+	output.Register("graphite", output.Registration{
+		Options:  func() any { return &netsink.Config{} },
+		Required: true,
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewGraphite(*opts.(*netsink.Config), env.Channels)
+		},
+	})
+}
+
+func NewGraphite(cfg netsink.Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	protocol := cfg.Protocol
+	if protocol == "" {
+		protocol = DefaultProtocol
+	}
+	g := &GraphiteOutput{
+		sink:     netsink.NewSink(protocol, cfg.Address, cfg.MaxPacketSize),
+		path:     netsink.NewMetricPath(cfg.PathTemplate, cfg.Device),
+		raw:      cfg.Raw,
+		channels: output.NewChannels(channels),
+	}
+	return g, nil
+}
+
+func (g *GraphiteOutput) Publish(readings []sensor.Reading) error {
+	return g.sink.Send(g.lines(readings))
+}
+
+func (g *GraphiteOutput) Close() error { return g.sink.Close() }
+
+// lines returns a "<path> <value> <unix seconds>" line per reading (and raw count).
+func (g *GraphiteOutput) lines(readings []sensor.Reading) []string {
+	var lines []string
+	for _, r := range readings {
+		ch := g.channels.Lookup(r.Channel)
+		path := g.path.Render(ch)
+		ts := strconv.FormatInt(timestamp(r.Timestamp), 10)
+		lines = append(lines, path+" "+strconv.FormatFloat(r.Value, 'f', -1, 64)+" "+ts)
+		if g.raw {
+			lines = append(lines, path+".raw "+strconv.Itoa(int(r.Raw))+" "+ts)
+		}
+	}
+	return lines
+}
+
+func timestamp(t time.Time) int64 {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.Unix()
+}
@@ -0,0 +1,52 @@
+package graphite
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output/netsink"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+func TestPublishTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sc := bufio.NewScanner(conn)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+
+	o, err := NewGraphite(netsink.Config{Address: ln.Addr().String(), Raw: true}, []config.ChannelConfig{{Channel: 0, Name: "battery"}})
+	if err != nil {
+		t.Fatalf("NewGraphite: %v", err)
+	}
+	defer o.Close()
+	ts := time.Unix(1758292914, 0)
+	if err := o.Publish([]sensor.Reading{{Channel: 0, Raw: 19023, Value: 3.72, Timestamp: ts}}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	for _, want := range []string{"ads1115.battery 3.72 1758292914", "ads1115.battery.raw 19023 1758292914"} {
+		select {
+		case got := <-lines:
+			if got != want {
+				t.Fatalf("got %q, want %q", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for %q", want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package homie
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Config holds the settings of the Homie convention output.
+type Config struct {
+	Server   string `json:"server"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	// BaseTopic is the Homie root topic. Default: "homie".
+	BaseTopic string `json:"base_topic,omitempty"`
+	// DeviceID is the Homie device id (lowercase letters, digits and hyphens). Default: "ads1115".
+	DeviceID string `json:"device_id,omitempty"`
+	// Name is the human-friendly device name. Default: "ADS1115".
+	Name string `json:"name,omitempty"`
+}
+
+// Validate checks the Homie device id.
+func (c Config) Validate() error {
+	if c.DeviceID != "" && !homieIDPattern.MatchString(c.DeviceID) {
+		return fmt.Errorf("invalid device_id %q: use lowercase letters, digits and hyphens", c.DeviceID)
+	}
+	if strings.ContainsAny(c.BaseTopic, "+#") {
+		return fmt.Errorf("invalid base_topic %q", c.BaseTopic)
+	}
+	return nil
+}
+
+// homieIDPattern matches valid Homie topic ids.
+var homieIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
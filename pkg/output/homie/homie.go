@@ -0,0 +1,266 @@
+// Package homie implements an output following the Homie 4 MQTT convention:
+// the ADS1115 is a Homie device with one node per enabled channel exposing
+// "value" and "raw" properties.
+package homie
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	// defaults used when the homie section leaves them empty
+	DefaultBaseTopic = "homie"
+	DefaultDeviceID  = "ads1115"
+	DefaultName      = "ADS1115"
+	homieVersion     = "4.0"
+	nodeType         = "adc"
+	// device lifecycle states
+	stateInit         = "init"
+	stateReady        = "ready"
+	stateLost         = "lost"
+	stateDisconnected = "disconnected"
+	// property ids
+	propValue = "value"
+	propRaw   = "raw"
+)
+
+// message is a retained Homie attribute or property value.
+type message struct {
+	Topic   string
+	Payload string
+}
+
+type HomieOutput struct {
+	client mqtt.Client
+	cfg    Config
+	base   string // <base_topic>/<device_id>
+
+	mu    sync.Mutex
+	nodes map[int]string
+	attrs []message
+}
+
+func init() {
+	output.Register("homie", output.Registration{
+		Options:  func() any { return &Config{} },
+		Required: true,
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewHomie(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+// NewHomie connects to the broker with a "$state = lost" will and publishes
+// the device description.
+func NewHomie(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg = withDefaults(cfg)
+	nodes, err := nodeIDs(channels)
+	if err != nil {
+		return nil, err
+	}
+	h := &HomieOutput{cfg: cfg, base: deviceTopic(cfg), nodes: nodes, attrs: deviceMessages(cfg, channels, nodes)}
+
+	clientID := cfg.ClientID
+	if clientID == "" {
+		clientID = "homie-" + cfg.DeviceID
+	}
+	opts := mqtt.NewClientOptions().AddBroker(cfg.Server).SetClientID(clientID)
+	if cfg.Username != "" {
+		opts.SetUsername(cfg.Username)
+	}
+	if cfg.Password != "" {
+		opts.SetPassword(cfg.Password)
+	}
+	opts.SetWill(h.base+"/$state", stateLost, 1, true)
+	// the description is republished on every connection since the will marks the device lost
+	opts.SetOnConnectHandler(h.onConnect)
+	h.client = mqtt.NewClient(opts)
+	token := h.client.Connect()
+	if token.Wait() && token.Error() != nil {
+		return nil, fmt.Errorf("homie connect: %w", token.Error())
+	}
+	return h, nil
+}
+
+func (h *HomieOutput) Publish(readings []sensor.Reading) error {
+	h.mu.Lock()
+	nodes := h.nodes
+	h.mu.Unlock()
+	for _, r := range readings {
+		node, ok := nodes[r.Channel]
+		if !ok {
+			continue
+		}
+		if err := h.publish(h.base+"/"+node+"/"+propValue, strconv.FormatFloat(r.Value, 'f', -1, 64)); err != nil {
+			return err
+		}
+		if err := h.publish(h.base+"/"+node+"/"+propRaw, strconv.Itoa(int(r.Raw))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close marks the device as disconnected and disconnects.
+func (h *HomieOutput) Close() error {
+	if h.client == nil {
+		return nil
+	}
+	_ = h.publish(h.base+"/$state", stateDisconnected)
+	h.client.Disconnect(250)
+	return nil
+}
+
+// UpdateChannels implements output.ChannelUpdater: when the enabled channels
+// or their attributes change, the device is announced again with its new nodes.
+// A channel set with colliding node ids is rejected and the current nodes kept.
+func (h *HomieOutput) UpdateChannels(channels []config.ChannelConfig) error {
+	nodes, err := nodeIDs(channels)
+	if err != nil {
+		return err
+	}
+	attrs := deviceMessages(h.cfg, channels, nodes)
+	h.mu.Lock()
+	if slices.Equal(attrs, h.attrs) {
+		h.mu.Unlock()
+		return nil
+	}
+	h.nodes, h.attrs = nodes, attrs
+	h.mu.Unlock()
+	// without a connection the new description is published on reconnect
+	if !h.client.IsConnectionOpen() {
+		return nil
+	}
+	return h.announce()
+}
+
+func (h *HomieOutput) onConnect(_ mqtt.Client) {
+	if err := h.announce(); err != nil {
+		log.Printf("homie: %v", err)
+	}
+}
+
+// announce publishes "$state = init", the device attributes and "$state = ready".
+func (h *HomieOutput) announce() error {
+	h.mu.Lock()
+	msgs := append([]message{{h.base + "/$state", stateInit}}, h.attrs...)
+	h.mu.Unlock()
+	msgs = append(msgs, message{h.base + "/$state", stateReady})
+	for _, m := range msgs {
+		if err := h.publish(m.Topic, m.Payload); err != nil {
+			return fmt.Errorf("publish %s: %w", m.Topic, err)
+		}
+	}
+	return nil
+}
+
+func (h *HomieOutput) publish(topic, payload string) error {
+	token := h.client.Publish(topic, 1, true, payload)
+	token.Wait()
+	return token.Error()
+}
+
+// deviceMessages returns the device, node and property attributes of the
+// channels with an id in nodes. cfg must have its defaults applied.
+func deviceMessages(cfg Config, channels []config.ChannelConfig, nodes map[int]string) []message {
+	base := deviceTopic(cfg)
+	var ids []string
+	var nodeMsgs []message
+	for _, ch := range channels {
+		id, ok := nodes[ch.Channel]
+		if !ok {
+			continue
+		}
+		ids = append(ids, id)
+		n := base + "/" + id
+		name := ch.Name
+		if name == "" {
+			name = fmt.Sprintf("Channel %d", ch.Channel)
+		}
+		nodeMsgs = append(nodeMsgs,
+			message{n + "/$name", name},
+			message{n + "/$type", nodeType},
+			message{n + "/$properties", propValue + "," + propRaw},
+			message{n + "/" + propValue + "/$name", "Value"},
+			message{n + "/" + propValue + "/$datatype", "float"},
+			message{n + "/" + propValue + "/$unit", ch.UnitOrDefault()},
+			message{n + "/" + propRaw + "/$name", "Raw"},
+			message{n + "/" + propRaw + "/$datatype", "integer"},
+			message{n + "/" + propRaw + "/$format", "-32768:32767"},
+		)
+	}
+	msgs := []message{
+		{base + "/$homie", homieVersion},
+		{base + "/$name", cfg.Name},
+		{base + "/$nodes", strings.Join(ids, ",")},
+		{base + "/$extensions", ""},
+	}
+	return append(msgs, nodeMsgs...)
+}
+
+// nodeIDs maps every enabled channel to its Homie node id: the channel name
+// converted to a valid id, or "ch<N>". It fails when "ch<N>" is already the id
+// of another channel, e.g. one named "ch1" when channel 1 has no name.
+func nodeIDs(channels []config.ChannelConfig) (map[int]string, error) {
+	ids := make(map[int]string)
+	used := make(map[string]int)
+	for _, ch := range channels {
+		if !ch.Enabled {
+			continue
+		}
+		id := homieID(ch.Name)
+		if _, taken := used[id]; id == "" || taken {
+			id = fmt.Sprintf("ch%d", ch.Channel)
+			if other, taken := used[id]; taken {
+				return nil, fmt.Errorf("homie: node id %q of channel %d is the name of channel %d; rename channel %d", id, ch.Channel, other, other)
+			}
+		}
+		used[id] = ch.Channel
+		ids[ch.Channel] = id
+	}
+	return ids, nil
+}
+
+// homieID lowercases s and replaces characters not allowed in Homie ids with hyphens.
+func homieID(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+func deviceTopic(cfg Config) string {
+	return strings.TrimSuffix(cfg.BaseTopic, "/") + "/" + cfg.DeviceID
+}
+
+// withDefaults fills the empty base topic, device id and name.
+func withDefaults(cfg Config) Config {
+	if cfg.BaseTopic == "" {
+		cfg.BaseTopic = DefaultBaseTopic
+	}
+	if cfg.DeviceID == "" {
+		cfg.DeviceID = DefaultDeviceID
+	}
+	if cfg.Name == "" {
+		cfg.Name = DefaultName
+	}
+	return cfg
+}
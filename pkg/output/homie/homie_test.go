@@ -0,0 +1,97 @@
+package homie
+
+import (
+	"testing"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+)
+
+func TestDeviceMessages(t *testing.T) {
+	cfg := withDefaults(Config{DeviceID: "garage"})
+	channels := []config.ChannelConfig{
+		{Channel: 0, Enabled: true, Name: "Battery Voltage"},
+		{Channel: 1, Enabled: false},
+		{Channel: 2, Enabled: true, Unit: "A"},
+	}
+	nodes, err := nodeIDs(channels)
+	if err != nil {
+		t.Fatalf("nodeIDs: %v", err)
+	}
+	got := make(map[string]string)
+	for _, m := range deviceMessages(cfg, channels, nodes) {
+		got[m.Topic] = m.Payload
+	}
+	want := map[string]string{
+		"homie/garage/$homie":                          "4.0",
+		"homie/garage/$name":                           "ADS1115",
+		"homie/garage/$nodes":                          "battery-voltage,ch2",
+		"homie/garage/$extensions":                     "",
+		"homie/garage/battery-voltage/$name":           "Battery Voltage",
+		"homie/garage/battery-voltage/$properties":     "value,raw",
+		"homie/garage/battery-voltage/value/$datatype": "float",
+		"homie/garage/battery-voltage/value/$unit":     "V",
+		"homie/garage/battery-voltage/raw/$datatype":   "integer",
+		"homie/garage/ch2/$name":                       "Channel 2",
+		"homie/garage/ch2/value/$unit":                 "A",
+	}
+	for topic, payload := range want {
+		if p, ok := got[topic]; !ok || p != payload {
+			t.Errorf("%s = %q, want %q", topic, got[topic], payload)
+		}
+	}
+	if _, ok := got["homie/garage/ch1/$name"]; ok {
+		t.Errorf("disabled channel published")
+	}
+}
+
+func TestNodeIDs(t *testing.T) {
+	ids, err := nodeIDs([]config.ChannelConfig{
+		{Channel: 0, Enabled: true, Name: "Tank"},
+		{Channel: 1, Enabled: true, Name: "tank"},
+		{Channel: 2, Enabled: true, Name: "__"},
+		{Channel: 3, Enabled: true, Name: "ch1"},
+	})
+	if err != nil {
+		t.Fatalf("nodeIDs: %v", err)
+	}
+	want := map[int]string{0: "tank", 1: "ch1", 2: "ch2", 3: "ch3"}
+	for ch, id := range want {
+		if ids[ch] != id {
+			t.Errorf("channel %d id = %q, want %q", ch, ids[ch], id)
+		}
+	}
+}
+
+func TestNodeIDCollision(t *testing.T) {
+	// channel 0 takes "ch1", the fallback id of the unnamed channel 1
+	_, err := nodeIDs([]config.ChannelConfig{{Channel: 0, Enabled: true, Name: "CH1"}, {Channel: 1, Enabled: true}})
+	if err == nil {
+		t.Fatal("expected an error for colliding node ids")
+	}
+}
+
+func TestUpdateChannels(t *testing.T) {
+	cfg := withDefaults(Config{})
+	channels := []config.ChannelConfig{{Channel: 0, Enabled: true}, {Channel: 1}}
+	nodes, _ := nodeIDs(channels)
+	h := &HomieOutput{client: mqtt.NewClient(mqtt.NewClientOptions()), cfg: cfg, nodes: nodes, attrs: deviceMessages(cfg, channels, nodes)}
+	channels[1].Enabled = true
+	// not connected: the new nodes are announced on connect
+	if err := h.UpdateChannels(channels); err != nil {
+		t.Fatalf("UpdateChannels: %v", err)
+	}
+	if h.nodes[1] != "ch1" {
+		t.Fatalf("nodes = %v", h.nodes)
+	}
+	// a colliding set is rejected and the nodes kept
+	channels[0].Name = "ch1"
+	if err := h.UpdateChannels(channels); err == nil || h.nodes[0] != "ch0" {
+		t.Fatalf("colliding update: err %v, nodes %v", err, h.nodes)
+	}
+	for _, m := range h.attrs {
+		if m.Topic == "homie/ads1115/$nodes" && m.Payload != "ch0,ch1" {
+			t.Fatalf("$nodes = %q", m.Payload)
+		}
+	}
+}
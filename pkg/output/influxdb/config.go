@@ -0,0 +1,78 @@
+package influxdb
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Config holds the settings of the InfluxDB line protocol output.
+type Config struct {
+	// URL is the base URL of the InfluxDB server, e.g. "http://localhost:8086".
+	URL string `json:"url"`
+	// APIVersion selects the write endpoint: 2 (/api/v2/write, default) or 1 (/write).
+	APIVersion int `json:"api_version,omitempty"`
+	// Token is sent as "Authorization: Token <token>".
+	Token string `json:"token,omitempty"`
+	// Username and Password authenticate v1 writes when no token is set.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Org and Bucket are the v2 write target.
+	Org    string `json:"org,omitempty"`
+	Bucket string `json:"bucket,omitempty"`
+	// Database and RetentionPolicy are the v1 write target.
+	Database        string `json:"database,omitempty"`
+	RetentionPolicy string `json:"retention_policy,omitempty"`
+	// Measurement is the line protocol measurement. Default: "ads1115".
+	Measurement string `json:"measurement,omitempty"`
+	// Device is the value of the "device" tag. Not set when empty.
+	Device string `json:"device,omitempty"`
+	// Tags are static tags added to every line. The channel, name and device
+	// tags are set by the output and cannot be used.
+	Tags map[string]string `json:"tags,omitempty"`
+	// ValueField and RawField name the calibrated value and raw count fields.
+	// Default: "value" and "raw". RawField "-" omits the raw count.
+	ValueField string `json:"value_field,omitempty"`
+	RawField   string `json:"raw_field,omitempty"`
+	// Gzip compresses request bodies.
+	Gzip bool `json:"gzip,omitempty"`
+	// BatchSize is the number of lines sent per request. Default: 1000.
+	BatchSize int `json:"batch_size,omitempty"`
+	// FlushIntervalMs is the maximum time lines wait before being sent. Default: 10000.
+	FlushIntervalMs int `json:"flush_interval_ms,omitempty"`
+	// Retries is the number of retries of a failed write. Default: 0.
+	Retries int `json:"retries,omitempty"`
+	// TimeoutMs is the HTTP request timeout. Default: 5000.
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+}
+
+// Validate checks the write target and batching settings.
+func (c Config) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q", c.URL)
+	}
+	switch c.APIVersion {
+	case 0, 2:
+		if c.Org == "" || c.Bucket == "" {
+			return fmt.Errorf("org and bucket are required for api_version 2")
+		}
+	case 1:
+		if c.Database == "" {
+			return fmt.Errorf("database is required for api_version 1")
+		}
+	default:
+		return fmt.Errorf("invalid api_version %d: use 1 or 2", c.APIVersion)
+	}
+	for k := range c.Tags {
+		switch k {
+		case "":
+			return fmt.Errorf("tags: empty tag key")
+		case tagChannel, tagName, tagDevice:
+			return fmt.Errorf("tags: %q is a built-in tag", k)
+		}
+	}
+	if c.BatchSize < 0 || c.FlushIntervalMs < 0 || c.TimeoutMs < 0 || c.Retries < 0 {
+		return fmt.Errorf("batch_size, flush_interval_ms, retries and timeout_ms must not be negative")
+	}
+	return nil
+}
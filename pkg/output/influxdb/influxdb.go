@@ -0,0 +1,269 @@
+// Package influxdb implements an output writing readings to InfluxDB in line
+// protocol over HTTP, using the v2 (/api/v2/write) or v1 (/write) endpoint.
+// Lines are batched and sent in the background when the batch is full or the
+// flush interval elapses.
+package influxdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	DefaultMeasurement     = "ads1115"
+	DefaultBatchSize       = 1000
+	DefaultFlushIntervalMs = 10000
+	DefaultTimeoutMs       = 5000
+	// delay before the first retry, doubled on every attempt
+	defaultRetryDelay = 500 * time.Millisecond
+)
+
+type InfluxDBOutput struct {
+	client     *http.Client
+	writeURL   string
+	token      string
+	username   string
+	password   string
+	gzip       bool
+	retries    int
+	retryDelay time.Duration
+	batchSize  int
+	enc        *lineEncoder
+
+	mu      sync.Mutex
+	pending []string
+	// sendMu serializes writes so batches reach the server in order
+	sendMu sync.Mutex
+	// full wakes the flush loop when a batch is full
+	full chan struct{}
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func init() {
+	output.Register("influxdb", output.Registration{
+		Options:  func() any { return &Config{} },
+		Required: true,
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewInfluxDB(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+// NewInfluxDB creates the output and starts the periodic flush.
+func NewInfluxDB(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	o, err := newInfluxDB(cfg, channels)
+	if err != nil {
+		return nil, err
+	}
+	interval := time.Duration(cfg.FlushIntervalMs) * time.Millisecond
+	if interval == 0 {
+		interval = DefaultFlushIntervalMs * time.Millisecond
+	}
+	o.wg.Add(1)
+	go o.flushLoop(interval)
+	return o, nil
+}
+
+func newInfluxDB(cfg Config, channels []config.ChannelConfig) (*InfluxDBOutput, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	writeURL, err := buildWriteURL(cfg)
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(cfg.TimeoutMs) * time.Millisecond
+	if timeout == 0 {
+		timeout = DefaultTimeoutMs * time.Millisecond
+	}
+	o := &InfluxDBOutput{
+		client:     &http.Client{Timeout: timeout},
+		writeURL:   writeURL,
+		token:      cfg.Token,
+		username:   cfg.Username,
+		password:   cfg.Password,
+		gzip:       cfg.Gzip,
+		retries:    cfg.Retries,
+		retryDelay: defaultRetryDelay,
+		batchSize:  cfg.BatchSize,
+		enc:        newLineEncoder(cfg, channels),
+		full:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	if o.batchSize == 0 {
+		o.batchSize = DefaultBatchSize
+	}
+	return o, nil
+}
+
+// buildWriteURL returns the write endpoint with its query parameters.
+func buildWriteURL(cfg Config) (string, error) {
+	u, err := url.Parse(strings.TrimSuffix(cfg.URL, "/"))
+	if err != nil {
+		return "", fmt.Errorf("influxdb url: %w", err)
+	}
+	q := url.Values{}
+	if cfg.APIVersion == 1 {
+		u.Path += "/write"
+		q.Set("db", cfg.Database)
+		if cfg.RetentionPolicy != "" {
+			q.Set("rp", cfg.RetentionPolicy)
+		}
+		q.Set("precision", "ms")
+	} else {
+		u.Path += "/api/v2/write"
+		q.Set("org", cfg.Org)
+		q.Set("bucket", cfg.Bucket)
+		q.Set("precision", "ms")
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Publish queues the readings and wakes the flush loop when a batch is full;
+// it never waits for the server. Failed background writes are logged, they
+// are not failures of the snapshot being published.
+func (o *InfluxDBOutput) Publish(readings []sensor.Reading) error {
+	o.mu.Lock()
+	o.pending = append(o.pending, o.enc.Encode(readings)...)
+	full := len(o.pending) >= o.batchSize
+	o.mu.Unlock()
+	if full {
+		select {
+		case o.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Close stops the periodic flush and writes the pending lines.
+func (o *InfluxDBOutput) Close() error {
+	close(o.done)
+	o.wg.Wait()
+	return o.flush()
+}
+
+func (o *InfluxDBOutput) flushLoop(interval time.Duration) {
+	defer o.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-o.full:
+		case <-o.done:
+			return
+		}
+		o.backgroundFlush()
+	}
+}
+
+// backgroundFlush writes the pending lines and logs a failed write.
+func (o *InfluxDBOutput) backgroundFlush() {
+	if err := o.flush(); err != nil {
+		log.Printf("warning: %v", err)
+	}
+}
+
+// flush writes the pending lines in batches of at most batchSize. Lines of a
+// batch that still fails after the retries are dropped.
+func (o *InfluxDBOutput) flush() error {
+	o.sendMu.Lock()
+	defer o.sendMu.Unlock()
+	o.mu.Lock()
+	lines := o.pending
+	o.pending = nil
+	o.mu.Unlock()
+	for len(lines) > 0 {
+		n := len(lines)
+		if n > o.batchSize {
+			n = o.batchSize
+		}
+		if err := o.write(lines[:n]); err != nil {
+			return fmt.Errorf("influxdb write (%d lines dropped): %w", len(lines), err)
+		}
+		lines = lines[n:]
+	}
+	return nil
+}
+
+// write sends one batch, retrying network errors, 429 and 5xx responses with
+// exponential backoff.
+func (o *InfluxDBOutput) write(lines []string) error {
+	body, err := o.body(lines)
+	if err != nil {
+		return err
+	}
+	delay := o.retryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := o.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= o.retries {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func (o *InfluxDBOutput) body(lines []string) ([]byte, error) {
+	data := []byte(strings.Join(lines, "\n") + "\n")
+	if !o.gzip {
+		return data, nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// post sends a request and reports whether a failure may be retried.
+func (o *InfluxDBOutput) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, o.writeURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if o.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	switch {
+	case o.token != "":
+		req.Header.Set("Authorization", "Token "+o.token)
+	case o.username != "":
+		req.SetBasicAuth(o.username, o.password)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,186 @@
+package influxdb
+
+import (
+	"compress/gzip"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+var testTS = time.UnixMilli(1758292914000)
+
+func TestEncodeLines(t *testing.T) {
+	cfg := Config{Device: "bench 1", Tags: map[string]string{"site": "lab,a"}, ValueField: "volts"}
+	channels := []config.ChannelConfig{{Channel: 0, Name: "battery"}}
+	lines := newLineEncoder(cfg, channels).Encode([]sensor.Reading{
+		{Channel: 0, Raw: 19023, Value: 3.72, Timestamp: testTS},
+		{Channel: 1, Raw: -12, Value: -0.0015, Timestamp: testTS},
+	})
+	want := []string{
+		`ads1115,channel=0,device=bench\ 1,name=battery,site=lab\,a volts=3.72,raw=19023i 1758292914000`,
+		`ads1115,channel=1,device=bench\ 1,site=lab\,a volts=-0.0015,raw=-12i 1758292914000`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines mismatch:\n got: %q\nwant: %q", lines, want)
+	}
+
+	cfg = Config{Measurement: "adc", RawField: "-"}
+	lines = newLineEncoder(cfg, nil).Encode([]sensor.Reading{{Channel: 2, Value: 1, Timestamp: testTS}})
+	if want := "adc,channel=2 value=1 1758292914000"; lines[0] != want {
+		t.Fatalf("got %q, want %q", lines[0], want)
+	}
+
+	// non-finite values are not valid line protocol
+	bad := []sensor.Reading{{Channel: 0, Raw: 32767, Value: math.Inf(1), Timestamp: testTS}, {Channel: 1, Value: math.NaN(), Timestamp: testTS}}
+	lines = newLineEncoder(Config{}, nil).Encode(bad)
+	if want := "ads1115,channel=0 raw=32767i 1758292914000\nads1115,channel=1 raw=0i 1758292914000"; strings.Join(lines, "\n") != want {
+		t.Fatalf("got %q, want %q", lines, want)
+	}
+	if lines = newLineEncoder(cfg, nil).Encode(bad); len(lines) != 0 {
+		t.Fatalf("lines without fields: %q", lines)
+	}
+}
+
+func TestConfigTags(t *testing.T) {
+	for _, tag := range []string{"channel", "name", "device", ""} {
+		cfg := Config{URL: "http://localhost:8086", Org: "o", Bucket: "b", Tags: map[string]string{tag: "x"}}
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("tag %q accepted", tag)
+		}
+	}
+}
+
+// recorder is a fake InfluxDB write endpoint failing the first fail requests with 503.
+type recorder struct {
+	mu       sync.Mutex
+	fail     int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	b, _ := io.ReadAll(body)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.requests = append(rec.requests, r)
+	if rec.fail > 0 {
+		rec.fail--
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	rec.bodies = append(rec.bodies, string(b))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestWriteV2(t *testing.T) {
+	rec := &recorder{fail: 1}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	cfg := Config{URL: srv.URL, Org: "acme", Bucket: "sensors", Token: "secret", Gzip: true, BatchSize: 2, Retries: 1}
+	o, err := newInfluxDB(cfg, nil)
+	if err != nil {
+		t.Fatalf("newInfluxDB: %v", err)
+	}
+	o.retryDelay = time.Millisecond
+
+	if err := o.Publish([]sensor.Reading{{Channel: 0, Value: 1, Timestamp: testTS}}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(rec.requests) != 0 {
+		t.Fatalf("batch sent before it was full")
+	}
+	if err := o.Publish([]sensor.Reading{{Channel: 1, Value: 2, Timestamp: testTS}}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(rec.requests) != 0 {
+		t.Fatalf("Publish wrote synchronously")
+	}
+	select {
+	case <-o.full:
+	default:
+		t.Fatalf("full batch did not wake the flush loop")
+	}
+	o.backgroundFlush()
+	if len(rec.requests) != 2 || len(rec.bodies) != 1 {
+		t.Fatalf("got %d requests and %d accepted bodies, want 2 and 1", len(rec.requests), len(rec.bodies))
+	}
+	r := rec.requests[1]
+	if r.URL.Path != "/api/v2/write" || r.URL.Query().Get("org") != "acme" || r.URL.Query().Get("bucket") != "sensors" || r.URL.Query().Get("precision") != "ms" {
+		t.Fatalf("unexpected url %s", r.URL)
+	}
+	if got := r.Header.Get("Authorization"); got != "Token secret" {
+		t.Fatalf("authorization = %q", got)
+	}
+	if want := "ads1115,channel=0 value=1,raw=0i 1758292914000\nads1115,channel=1 value=2,raw=0i 1758292914000\n"; rec.bodies[0] != want {
+		t.Fatalf("body = %q, want %q", rec.bodies[0], want)
+	}
+}
+
+func TestWriteV1(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	cfg := Config{URL: srv.URL, APIVersion: 1, Database: "adc", RetentionPolicy: "week", Username: "u", Password: "p"}
+	o, err := newInfluxDB(cfg, nil)
+	if err != nil {
+		t.Fatalf("newInfluxDB: %v", err)
+	}
+	_ = o.Publish([]sensor.Reading{{Channel: 0, Value: 1, Timestamp: testTS}})
+	close(o.done)
+	if err := o.flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	r := rec.requests[0]
+	if r.URL.Path != "/write" || r.URL.Query().Get("db") != "adc" || r.URL.Query().Get("rp") != "week" {
+		t.Fatalf("unexpected url %s", r.URL)
+	}
+	if u, p, ok := r.BasicAuth(); !ok || u != "u" || p != "p" {
+		t.Fatalf("basic auth = %q %q %v", u, p, ok)
+	}
+}
+
+func TestWriteClientErrorNotRetried(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "bad line", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	o, err := newInfluxDB(Config{URL: srv.URL, Org: "o", Bucket: "b", BatchSize: 1, Retries: 3}, nil)
+	if err != nil {
+		t.Fatalf("newInfluxDB: %v", err)
+	}
+	o.retryDelay = time.Millisecond
+	reading := []sensor.Reading{{Channel: 0, Timestamp: testTS}}
+	if err := o.Publish(reading); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	o.backgroundFlush()
+	if calls != 1 {
+		t.Fatalf("got %d calls, want 1", calls)
+	}
+	// a failed background write is not the failure of later snapshots
+	if err := o.Publish(reading); err != nil {
+		t.Fatalf("publish after a failed write: %v", err)
+	}
+	if len(o.pending) != 1 {
+		t.Fatalf("%d pending lines, want the new snapshot queued", len(o.pending))
+	}
+}
@@ -0,0 +1,128 @@
+package influxdb
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	tagDevice    = "device"
+	tagChannel   = "channel"
+	tagName      = "name"
+	omitField    = "-"
+	defaultValue = "value"
+	defaultRaw   = "raw"
+)
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "\n", `\n`)
+	keyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
+)
+
+// lineEncoder renders readings as InfluxDB line protocol with millisecond timestamps.
+type lineEncoder struct {
+	measurement string
+	tags        map[string]string
+	valueField  string
+	rawField    string
+	names       map[int]string
+}
+
+func newLineEncoder(cfg Config, channels []config.ChannelConfig) *lineEncoder {
+	e := &lineEncoder{
+		measurement: measurementEscaper.Replace(cfg.Measurement),
+		valueField:  keyEscaper.Replace(cfg.ValueField),
+		rawField:    cfg.RawField,
+		names:       make(map[int]string),
+	}
+	if e.measurement == "" {
+		e.measurement = DefaultMeasurement
+	}
+	if e.valueField == "" {
+		e.valueField = defaultValue
+	}
+	switch e.rawField {
+	case "":
+		e.rawField = defaultRaw
+	case omitField:
+	default:
+		e.rawField = keyEscaper.Replace(e.rawField)
+	}
+	e.tags = make(map[string]string, len(cfg.Tags)+1)
+	for k, v := range cfg.Tags {
+		e.tags[k] = v
+	}
+	if cfg.Device != "" {
+		e.tags[tagDevice] = cfg.Device
+	}
+	for _, ch := range channels {
+		e.names[ch.Channel] = ch.Name
+	}
+	return e
+}
+
+// Encode returns one line per reading. Tags are sorted by key as recommended
+// by InfluxDB. Line protocol has no NaN or infinite floats: such a value is
+// left out, and so is the line when it has no other field.
+func (e *lineEncoder) Encode(readings []sensor.Reading) []string {
+	lines := make([]string, 0, len(readings))
+	for _, r := range readings {
+		finite := !math.IsNaN(r.Value) && !math.IsInf(r.Value, 0)
+		if !finite && e.rawField == omitField {
+			continue
+		}
+		tags := make(map[string]string, len(e.tags)+2)
+		for k, v := range e.tags {
+			tags[k] = v
+		}
+		tags[tagChannel] = strconv.Itoa(r.Channel)
+		tags[tagName] = e.names[r.Channel]
+		keys := make([]string, 0, len(tags))
+		for k := range tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var b strings.Builder
+		b.WriteString(e.measurement)
+		for _, k := range keys {
+			appendTag(&b, k, tags[k])
+		}
+		b.WriteByte(' ')
+		if finite {
+			b.WriteString(e.valueField)
+			b.WriteByte('=')
+			b.WriteString(strconv.FormatFloat(r.Value, 'f', -1, 64))
+		}
+		if e.rawField != omitField {
+			if finite {
+				b.WriteByte(',')
+			}
+			b.WriteString(e.rawField)
+			b.WriteByte('=')
+			b.WriteString(strconv.Itoa(int(r.Raw)))
+			b.WriteByte('i')
+		}
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatInt(r.Timestamp.UnixMilli(), 10))
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// appendTag appends ",key=value"; tags with an empty value are not allowed by
+// line protocol and are skipped.
+func appendTag(b *strings.Builder, key, value string) {
+	if key == "" || value == "" {
+		return
+	}
+	b.WriteByte(',')
+	b.WriteString(keyEscaper.Replace(key))
+	b.WriteByte('=')
+	b.WriteString(keyEscaper.Replace(value))
+}
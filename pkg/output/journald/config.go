@@ -0,0 +1,43 @@
+package journald
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output/syslog"
+)
+
+// journaldField matches a journal field name: uppercase letters, digits and
+// underscores, not starting with an underscore.
+var journaldField = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_]*$`)
+
+// Config holds the settings of the journald output.
+type Config struct {
+	// Socket is the journald native protocol socket. Default: "/run/systemd/journal/socket".
+	Socket string `json:"socket,omitempty"`
+	// Identifier is SYSLOG_IDENTIFIER. Default: "ads1115".
+	Identifier string `json:"identifier,omitempty"`
+	// Severity is the PRIORITY name, as for syslog. Default: "info".
+	Severity string `json:"severity,omitempty"`
+	// FieldPrefix prefixes the reading fields (CHANNEL, NAME, UNIT, RAW, VALUE).
+	// Default: "ADS1115_".
+	FieldPrefix string `json:"field_prefix,omitempty"`
+	// Fields are extra fields added to every entry.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Validate checks the severity and field names.
+func (c Config) Validate() error {
+	if _, ok := syslog.Severities[c.Severity]; c.Severity != "" && !ok {
+		return fmt.Errorf("invalid severity %q", c.Severity)
+	}
+	if c.FieldPrefix != "" && !journaldField.MatchString(c.FieldPrefix) {
+		return fmt.Errorf("invalid field_prefix %q", c.FieldPrefix)
+	}
+	for k := range c.Fields {
+		if !journaldField.MatchString(k) {
+			return fmt.Errorf("invalid field name %q", k)
+		}
+	}
+	return nil
+}
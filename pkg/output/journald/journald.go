@@ -0,0 +1,162 @@
+// Package journald implements an output writing one journal entry per reading
+// with structured fields, using the journald native protocol.
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output/syslog"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	DefaultSocket      = "/run/systemd/journal/socket"
+	DefaultIdentifier  = "ads1115"
+	DefaultSeverity    = "info"
+	DefaultFieldPrefix = "ADS1115_"
+)
+
+type JournaldOutput struct {
+	socket     string
+	identifier string
+	priority   string
+	prefix     string
+	fields     []string // extra fields, sorted by name, as "NAME=value"
+	channels   output.Channels
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func init() {
+	output.Register("journald", output.Registration{
+		Options: func() any { return &Config{} },
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewJournald(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+func NewJournald(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	j := &JournaldOutput{
+		socket:     cfg.Socket,
+		identifier: cfg.Identifier,
+		prefix:     cfg.FieldPrefix,
+		channels:   output.NewChannels(channels),
+	}
+	if j.socket == "" {
+		j.socket = DefaultSocket
+	}
+	if j.identifier == "" {
+		j.identifier = DefaultIdentifier
+	}
+	if j.prefix == "" {
+		j.prefix = DefaultFieldPrefix
+	}
+	severity := cfg.Severity
+	if severity == "" {
+		severity = DefaultSeverity
+	}
+	j.priority = strconv.Itoa(syslog.Severities[severity])
+	for k, v := range cfg.Fields {
+		j.fields = append(j.fields, k+"="+v)
+	}
+	sort.Strings(j.fields)
+	return j, nil
+}
+
+// Publish sends one entry per reading; a failed write is retried once on a
+// new connection (journald may have been restarted).
+func (j *JournaldOutput) Publish(readings []sensor.Reading) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, r := range readings {
+		if err := j.send(j.entry(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *JournaldOutput) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.conn == nil {
+		return nil
+	}
+	err := j.conn.Close()
+	j.conn = nil
+	return err
+}
+
+// entry serializes the fields of a reading.
+func (j *JournaldOutput) entry(r sensor.Reading) []byte {
+	ch := j.channels.Lookup(r.Channel)
+	name := ch.Name
+	if name == "" {
+		name = fmt.Sprintf("ch%d", r.Channel)
+	}
+	value := strconv.FormatFloat(r.Value, 'f', -1, 64)
+	var buf bytes.Buffer
+	field(&buf, "MESSAGE", fmt.Sprintf("%s=%s %s", name, value, ch.UnitOrDefault()))
+	field(&buf, "PRIORITY", j.priority)
+	field(&buf, "SYSLOG_IDENTIFIER", j.identifier)
+	field(&buf, j.prefix+"CHANNEL", strconv.Itoa(r.Channel))
+	if ch.Name != "" {
+		field(&buf, j.prefix+"NAME", ch.Name)
+	}
+	field(&buf, j.prefix+"UNIT", ch.UnitOrDefault())
+	field(&buf, j.prefix+"RAW", strconv.Itoa(int(r.Raw)))
+	field(&buf, j.prefix+"VALUE", value)
+	field(&buf, j.prefix+"TIMESTAMP", strconv.FormatInt(r.Timestamp.UnixMicro(), 10))
+	for _, f := range j.fields {
+		k, v, _ := strings.Cut(f, "=")
+		field(&buf, k, v)
+	}
+	return buf.Bytes()
+}
+
+// field appends NAME=value, or the binary form (name, newline, 64-bit
+// little-endian length, value) when the value contains a newline.
+func field(buf *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(name + "=" + value + "\n")
+		return
+	}
+	buf.WriteString(name + "\n")
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+}
+
+func (j *JournaldOutput) send(b []byte) error {
+	for attempt := 0; ; attempt++ {
+		if j.conn == nil {
+			conn, err := net.Dial("unixgram", j.socket)
+			if err != nil {
+				return fmt.Errorf("journald dial %s: %w", j.socket, err)
+			}
+			j.conn = conn
+		}
+		_, err := j.conn.Write(b)
+		if err == nil {
+			return nil
+		}
+		j.conn.Close()
+		j.conn = nil
+		if attempt > 0 {
+			return fmt.Errorf("journald write %s: %w", j.socket, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package journald
+
+import (
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+func TestPublish(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+	pc, err := net.ListenPacket("unixgram", socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer pc.Close()
+	cfg := Config{Socket: socket, Severity: "warning", Fields: map[string]string{"SITE": "lab\nbench"}}
+	o, err := NewJournald(cfg, []config.ChannelConfig{{Channel: 2, Name: "battery"}})
+	if err != nil {
+		t.Fatalf("NewJournald: %v", err)
+	}
+	defer o.Close()
+	ts := time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)
+	if err := o.Publish([]sensor.Reading{{Channel: 2, Raw: -12, Value: -0.0015, Timestamp: ts}}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	buf := make([]byte, 1024)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := "MESSAGE=battery=-0.0015 V\nPRIORITY=4\nSYSLOG_IDENTIFIER=ads1115\n" +
+		"ADS1115_CHANNEL=2\nADS1115_NAME=battery\nADS1115_UNIT=V\nADS1115_RAW=-12\nADS1115_VALUE=-0.0015\n" +
+		"ADS1115_TIMESTAMP=1758292914000000\nSITE\n" + string(binary.LittleEndian.AppendUint64(nil, 9)) + "lab\nbench\n"
+	if got := string(buf[:n]); got != want {
+		t.Fatalf("entry mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
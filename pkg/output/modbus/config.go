@@ -0,0 +1,54 @@
+package modbus
+
+import (
+	"fmt"
+	"net"
+)
+
+// Modbus register byte and word orders.
+const (
+	OrderBig    = "big"
+	OrderLittle = "little"
+)
+
+// Config holds the settings of the Modbus TCP server output.
+type Config struct {
+	// Address is the listen address. Default: ":502".
+	Address string `json:"address,omitempty"`
+	// UnitID is the unit identifier answered; 0 answers every unit id.
+	UnitID int `json:"unit_id,omitempty"`
+	// ByteOrder is the byte order inside a register and WordOrder the register
+	// order of 32-bit floats: "big" (default) or "little".
+	ByteOrder string `json:"byte_order,omitempty"`
+	WordOrder string `json:"word_order,omitempty"`
+	// InputBase is the first input register; channel N uses registers
+	// InputBase+3N (raw int16) and InputBase+3N+1..2 (float32 value).
+	InputBase int `json:"input_base,omitempty"`
+	// Calibration exposes holding registers with each channel calibration;
+	// channel N uses HoldingBase+4N..+1 (float32 scale) and HoldingBase+4N+2..3
+	// (float32 offset). Writes update the running configuration.
+	Calibration bool `json:"calibration,omitempty"`
+	HoldingBase int  `json:"holding_base,omitempty"`
+}
+
+// Validate checks the address, unit id, orders and register bases.
+func (c Config) Validate() error {
+	if c.Address != "" {
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			return fmt.Errorf("invalid address %q: %w", c.Address, err)
+		}
+	}
+	if c.UnitID < 0 || c.UnitID > 255 {
+		return fmt.Errorf("invalid unit_id %d", c.UnitID)
+	}
+	for _, o := range []string{c.ByteOrder, c.WordOrder} {
+		if o != "" && o != OrderBig && o != OrderLittle {
+			return fmt.Errorf("invalid order %q: use %s or %s", o, OrderBig, OrderLittle)
+		}
+	}
+	// 4 channels use 12 input and 16 holding registers
+	if c.InputBase < 0 || c.InputBase > 0xFFFF-12 || c.HoldingBase < 0 || c.HoldingBase > 0xFFFF-16 {
+		return fmt.Errorf("input_base and holding_base must leave room for 4 channels in 0..65535")
+	}
+	return nil
+}
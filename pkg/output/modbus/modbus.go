@@ -0,0 +1,406 @@
+// Package modbus implements an output running a Modbus TCP server (slave)
+// that serves the latest reading of each channel as input registers and,
+// optionally, the channel calibration as writable holding registers.
+package modbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/control"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	DefaultAddress = ":502"
+
+	numChannels      = 4
+	inputsPerChannel = 3
+	holdingPerChan   = 4
+	mbapLen          = 7
+	maxPDU           = 253
+	maxReadQuantity  = 125
+	maxWriteQuantity = 123
+	idleTimeout      = 5 * time.Minute
+)
+
+// Function codes.
+const (
+	fcReadHolding   = 0x03
+	fcReadInput     = 0x04
+	fcWriteSingle   = 0x06
+	fcWriteMultiple = 0x10
+)
+
+// Exception codes.
+const (
+	exIllegalFunction = 0x01
+	exIllegalAddress  = 0x02
+	exIllegalValue    = 0x03
+	exDeviceFailure   = 0x04
+)
+
+type ModbusOutput struct {
+	cfg       Config
+	byteOrder binary.ByteOrder
+	wordSwap  bool
+	ln        net.Listener
+
+	mu       sync.Mutex
+	latest   map[int]sensor.Reading
+	channels map[int]config.ChannelConfig // calibration used without a command handler
+	handler  control.Handler
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+func init() {
+	output.Register("modbus", output.Registration{
+		Options: func() any { return &Config{} },
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewModbus(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+// NewModbus starts listening on the configured address.
+func NewModbus(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Address == "" {
+		cfg.Address = DefaultAddress
+	}
+	ln, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("modbus listen: %w", err)
+	}
+	m := newModbus(cfg, channels)
+	m.ln = ln
+	m.wg.Add(1)
+	go m.serve()
+	return m, nil
+}
+
+func newModbus(cfg Config, channels []config.ChannelConfig) *ModbusOutput {
+	m := &ModbusOutput{
+		cfg:       cfg,
+		byteOrder: binary.BigEndian,
+		wordSwap:  cfg.WordOrder == OrderLittle,
+		latest:    make(map[int]sensor.Reading),
+		channels:  make(map[int]config.ChannelConfig),
+		conns:     make(map[net.Conn]struct{}),
+	}
+	if cfg.ByteOrder == OrderLittle {
+		m.byteOrder = binary.LittleEndian
+	}
+	for _, ch := range channels {
+		m.channels[ch.Channel] = ch
+	}
+	return m
+}
+
+// Publish stores the readings served by the following requests.
+func (m *ModbusOutput) Publish(readings []sensor.Reading) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range readings {
+		m.latest[r.Channel] = r
+	}
+	return nil
+}
+
+// SetCommandHandler implements control.Commandable: holding register reads
+// and writes go through h so they reflect and change the running configuration.
+func (m *ModbusOutput) SetCommandHandler(h control.Handler) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handler = h
+	return nil
+}
+
+// Close stops the listener and closes the client connections.
+func (m *ModbusOutput) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	var err error
+	if m.ln != nil {
+		err = m.ln.Close()
+	}
+	for c := range m.conns {
+		c.Close()
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+	return err
+}
+
+func (m *ModbusOutput) serve() {
+	defer m.wg.Done()
+	for {
+		conn, err := m.ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("warning: modbus accept: %v", err)
+			}
+			return
+		}
+		m.mu.Lock()
+		if m.closed {
+			m.mu.Unlock()
+			conn.Close()
+			return
+		}
+		m.conns[conn] = struct{}{}
+		m.wg.Add(1)
+		m.mu.Unlock()
+		go m.serveConn(conn)
+	}
+}
+
+// serveConn answers the requests of one client until it disconnects, sends
+// a malformed frame or stays idle for too long.
+func (m *ModbusOutput) serveConn(conn net.Conn) {
+	defer m.wg.Done()
+	defer func() {
+		m.mu.Lock()
+		delete(m.conns, conn)
+		m.mu.Unlock()
+		conn.Close()
+	}()
+	header := make([]byte, mbapLen)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		// MBAP: transaction id, protocol id (0), length (unit id + PDU), unit id
+		length := int(binary.BigEndian.Uint16(header[4:6]))
+		if binary.BigEndian.Uint16(header[2:4]) != 0 || length < 2 || length > maxPDU+1 {
+			return
+		}
+		pdu := make([]byte, length-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+		unit := header[6]
+		if m.cfg.UnitID != 0 && int(unit) != m.cfg.UnitID {
+			// requests for other units are not answered, as on a gateway without that unit
+			continue
+		}
+		resp := m.handlePDU(pdu)
+		out := make([]byte, mbapLen+len(resp))
+		copy(out, header[:4])
+		binary.BigEndian.PutUint16(out[4:6], uint16(len(resp)+1))
+		out[6] = unit
+		copy(out[mbapLen:], resp)
+		if _, err := conn.Write(out); err != nil {
+			return
+		}
+	}
+}
+
+// handlePDU executes a request PDU and returns the response PDU.
+func (m *ModbusOutput) handlePDU(pdu []byte) []byte {
+	fc := pdu[0]
+	switch fc {
+	case fcReadInput:
+		return m.read(pdu, m.cfg.InputBase, m.inputRegisters())
+	case fcReadHolding:
+		if !m.cfg.Calibration {
+			return exception(fc, exIllegalFunction)
+		}
+		return m.read(pdu, m.cfg.HoldingBase, m.holdingRegisters())
+	case fcWriteMultiple:
+		if !m.cfg.Calibration {
+			return exception(fc, exIllegalFunction)
+		}
+		return m.writeMultiple(pdu)
+	case fcWriteSingle:
+		if !m.cfg.Calibration {
+			return exception(fc, exIllegalFunction)
+		}
+		// calibration values are float32 pairs: a single register is half a value
+		return exception(fc, exIllegalAddress)
+	default:
+		return exception(fc, exIllegalFunction)
+	}
+}
+
+// read answers function codes 3 and 4 from the register block starting at base.
+func (m *ModbusOutput) read(pdu []byte, base int, regs []uint16) []byte {
+	fc := pdu[0]
+	if len(pdu) != 5 {
+		return exception(fc, exIllegalValue)
+	}
+	addr := int(binary.BigEndian.Uint16(pdu[1:3]))
+	qty := int(binary.BigEndian.Uint16(pdu[3:5]))
+	if qty < 1 || qty > maxReadQuantity {
+		return exception(fc, exIllegalValue)
+	}
+	if addr < base || addr+qty > base+len(regs) {
+		return exception(fc, exIllegalAddress)
+	}
+	resp := make([]byte, 2, 2+2*qty)
+	resp[0], resp[1] = fc, byte(2*qty)
+	for _, v := range regs[addr-base : addr-base+qty] {
+		resp = append(resp, 0, 0)
+		m.byteOrder.PutUint16(resp[len(resp)-2:], v)
+	}
+	return resp
+}
+
+// writeMultiple answers function code 16: the written registers must cover
+// whole float32 values, each of which becomes a set_calibration command. The
+// values of every channel are checked before any is applied.
+func (m *ModbusOutput) writeMultiple(pdu []byte) []byte {
+	fc := pdu[0]
+	if len(pdu) < 6 {
+		return exception(fc, exIllegalValue)
+	}
+	addr := int(binary.BigEndian.Uint16(pdu[1:3]))
+	qty := int(binary.BigEndian.Uint16(pdu[3:5]))
+	if qty < 1 || qty > maxWriteQuantity || int(pdu[5]) != 2*qty || len(pdu) != 6+2*qty {
+		return exception(fc, exIllegalValue)
+	}
+	start := addr - m.cfg.HoldingBase
+	if start < 0 || start+qty > numChannels*holdingPerChan || start%2 != 0 || qty%2 != 0 {
+		return exception(fc, exIllegalAddress)
+	}
+	m.mu.Lock()
+	h := m.handler
+	m.mu.Unlock()
+	if h == nil {
+		return exception(fc, exDeviceFailure)
+	}
+	values := make([]uint16, qty)
+	for i := range values {
+		values[i] = m.byteOrder.Uint16(pdu[6+2*i:])
+	}
+	cmds := make(map[int]*control.Command)
+	for i := 0; i < qty; i += 2 {
+		reg := start + i
+		ch := reg / holdingPerChan
+		cmd, ok := cmds[ch]
+		if !ok {
+			c := ch
+			cmd = &control.Command{Command: control.CmdSetCalibration, Channel: &c}
+			cmds[ch] = cmd
+		}
+		v := float64(m.float(values[i : i+2]))
+		if reg%holdingPerChan == 0 {
+			cmd.CalibrationScale = &v
+		} else {
+			cmd.CalibrationOffset = &v
+		}
+	}
+	for ch, cmd := range cmds {
+		if err := cmd.ValidateCalibration(); err != nil {
+			log.Printf("warning: modbus: set calibration of channel %d: %v", ch, err)
+			return exception(fc, exIllegalValue)
+		}
+	}
+	for ch := 0; ch < numChannels; ch++ {
+		cmd, ok := cmds[ch]
+		if !ok {
+			continue
+		}
+		resp := h.Handle(*cmd)
+		if !resp.OK {
+			log.Printf("warning: modbus: set calibration of channel %d: %s", ch, resp.Error)
+			return exception(fc, exDeviceFailure)
+		}
+		if c, ok := resp.Result.(config.ChannelConfig); ok {
+			m.mu.Lock()
+			m.channels[ch] = c
+			m.mu.Unlock()
+		}
+	}
+	return pdu[:5]
+}
+
+// inputRegisters returns the input register block: per channel the raw value
+// followed by the float32 value (NaN until the channel has been read).
+func (m *ModbusOutput) inputRegisters() []uint16 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	regs := make([]uint16, numChannels*inputsPerChannel)
+	for ch := 0; ch < numChannels; ch++ {
+		value := float32(math.NaN())
+		if r, ok := m.latest[ch]; ok {
+			regs[ch*inputsPerChannel] = uint16(r.Raw)
+			value = float32(r.Value)
+		}
+		m.putFloat(regs[ch*inputsPerChannel+1:], value)
+	}
+	return regs
+}
+
+// holdingRegisters returns the calibration block: per channel the scale and
+// offset as float32, taken from the running configuration when available.
+func (m *ModbusOutput) holdingRegisters() []uint16 {
+	channels := m.calibration()
+	regs := make([]uint16, numChannels*holdingPerChan)
+	for ch := 0; ch < numChannels; ch++ {
+		c := channels[ch]
+		m.putFloat(regs[ch*holdingPerChan:], float32(c.CalibrationScale))
+		m.putFloat(regs[ch*holdingPerChan+2:], float32(c.CalibrationOffset))
+	}
+	return regs
+}
+
+func (m *ModbusOutput) calibration() map[int]config.ChannelConfig {
+	m.mu.Lock()
+	h := m.handler
+	channels := make(map[int]config.ChannelConfig, len(m.channels))
+	for k, v := range m.channels {
+		channels[k] = v
+	}
+	m.mu.Unlock()
+	if h == nil {
+		return channels
+	}
+	if cfg, ok := h.Handle(control.Command{Command: control.CmdGetConfig}).Result.(config.Config); ok {
+		for _, c := range cfg.Channels {
+			channels[c.Channel] = c
+		}
+	}
+	return channels
+}
+
+// putFloat stores v in regs[0:2] in the configured word order.
+func (m *ModbusOutput) putFloat(regs []uint16, v float32) {
+	bits := math.Float32bits(v)
+	hi, lo := uint16(bits>>16), uint16(bits)
+	if m.wordSwap {
+		hi, lo = lo, hi
+	}
+	regs[0], regs[1] = hi, lo
+}
+
+// float decodes a float32 stored in regs[0:2] in the configured word order.
+func (m *ModbusOutput) float(regs []uint16) float32 {
+	hi, lo := regs[0], regs[1]
+	if m.wordSwap {
+		hi, lo = lo, hi
+	}
+	return math.Float32frombits(uint32(hi)<<16 | uint32(lo))
+}
+
+func exception(fc, code byte) []byte {
+	return []byte{fc | 0x80, code}
+}
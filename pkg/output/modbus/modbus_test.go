@@ -0,0 +1,147 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/control"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+// fakeHandler applies set_calibration commands to its config.
+type fakeHandler struct {
+	cfg  config.Config
+	cmds []control.Command
+}
+
+func (h *fakeHandler) Handle(cmd control.Command) control.Response {
+	h.cmds = append(h.cmds, cmd)
+	switch cmd.Command {
+	case control.CmdGetConfig:
+		return control.OK(cmd, h.cfg)
+	case control.CmdSetCalibration:
+		ch := &h.cfg.Channels[*cmd.Channel]
+		if cmd.CalibrationScale != nil {
+			ch.CalibrationScale = *cmd.CalibrationScale
+		}
+		if cmd.CalibrationOffset != nil {
+			ch.CalibrationOffset = *cmd.CalibrationOffset
+		}
+		return control.OK(cmd, *ch)
+	}
+	return control.Errorf(cmd, "unexpected")
+}
+
+func request(t *testing.T, conn net.Conn, unit byte, pdu []byte) []byte {
+	t.Helper()
+	req := make([]byte, mbapLen, mbapLen+len(pdu))
+	binary.BigEndian.PutUint16(req[0:2], 0x1234)
+	binary.BigEndian.PutUint16(req[4:6], uint16(len(pdu)+1))
+	req[6] = unit
+	if _, err := conn.Write(append(req, pdu...)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	header := make([]byte, mbapLen)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		t.Fatalf("read header: %v", err)
+	}
+	if binary.BigEndian.Uint16(header[0:2]) != 0x1234 || header[6] != unit {
+		t.Fatalf("unexpected header %x", header)
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(header[4:6])-1)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		t.Fatalf("read pdu: %v", err)
+	}
+	return resp
+}
+
+func readPDU(fc byte, addr, qty uint16) []byte {
+	return []byte{fc, byte(addr >> 8), byte(addr), byte(qty >> 8), byte(qty)}
+}
+
+func floatBytes(v float32) []byte {
+	return binary.BigEndian.AppendUint32(nil, math.Float32bits(v))
+}
+
+func TestServer(t *testing.T) {
+	channels := []config.ChannelConfig{{Channel: 0, CalibrationScale: 1}, {Channel: 1, CalibrationScale: 2, CalibrationOffset: 0.5}, {Channel: 2}, {Channel: 3}}
+	o, err := NewModbus(Config{Address: "127.0.0.1:0", UnitID: 7, InputBase: 100, Calibration: true}, channels)
+	if err != nil {
+		t.Fatalf("NewModbus: %v", err)
+	}
+	defer o.Close()
+	m := o.(*ModbusOutput)
+	h := &fakeHandler{cfg: config.Config{Channels: channels}}
+	_ = m.SetCommandHandler(h)
+	_ = o.Publish([]sensor.Reading{{Channel: 1, Raw: -12, Value: 3.5}})
+
+	conn, err := net.Dial("tcp", m.ln.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// channel 1 input registers: raw then float value
+	got := request(t, conn, 7, readPDU(fcReadInput, 103, 3))
+	want := append([]byte{fcReadInput, 6, 0xff, 0xf4}, floatBytes(3.5)...)
+	if string(got) != string(want) {
+		t.Fatalf("read input = %x, want %x", got, want)
+	}
+	// a channel never read is NaN
+	got = request(t, conn, 7, readPDU(fcReadInput, 107, 2))
+	if v := math.Float32frombits(binary.BigEndian.Uint32(got[2:])); !math.IsNaN(float64(v)) {
+		t.Fatalf("unread channel value = %v, want NaN", v)
+	}
+	if got := request(t, conn, 7, readPDU(fcReadInput, 99, 2)); string(got) != string([]byte{fcReadInput | 0x80, exIllegalAddress}) {
+		t.Fatalf("out of range read = %x", got)
+	}
+
+	// write channel 1 offset and read back the calibration
+	write := append([]byte{fcWriteMultiple, 0, 6, 0, 2, 4}, floatBytes(-1.25)...)
+	if got := request(t, conn, 7, write); string(got) != string(write[:5]) {
+		t.Fatalf("write = %x", got)
+	}
+	if c := h.cfg.Channels[1]; c.CalibrationOffset != -1.25 || c.CalibrationScale != 2 {
+		t.Fatalf("calibration = %+v", c)
+	}
+	got = request(t, conn, 7, readPDU(fcReadHolding, 4, 4))
+	want = append(append([]byte{fcReadHolding, 8}, floatBytes(2)...), floatBytes(-1.25)...)
+	if string(got) != string(want) {
+		t.Fatalf("read holding = %x, want %x", got, want)
+	}
+	// a zero scale for channel 1 rejects the whole write, channel 0 offset included
+	write = append(append([]byte{fcWriteMultiple, 0, 2, 0, 4, 8}, floatBytes(0.75)...), floatBytes(0)...)
+	if got := request(t, conn, 7, write); string(got) != string([]byte{fcWriteMultiple | 0x80, exIllegalValue}) {
+		t.Fatalf("zero scale write = %x", got)
+	}
+	if c := h.cfg.Channels[0]; c.CalibrationOffset != 0 {
+		t.Fatalf("channel 0 calibration applied: %+v", c)
+	}
+	// half a float is rejected
+	if got := request(t, conn, 7, []byte{fcWriteMultiple, 0, 5, 0, 1, 2, 0, 0}); string(got) != string([]byte{fcWriteMultiple | 0x80, exIllegalAddress}) {
+		t.Fatalf("misaligned write = %x", got)
+	}
+	if got := request(t, conn, 7, []byte{0x01, 0, 0, 0, 1}); string(got) != string([]byte{0x81, exIllegalFunction}) {
+		t.Fatalf("unsupported function = %x", got)
+	}
+}
+
+func TestOrders(t *testing.T) {
+	m := newModbus(Config{ByteOrder: OrderLittle, WordOrder: OrderLittle}, nil)
+	_ = m.Publish([]sensor.Reading{{Channel: 0, Raw: 0x0102, Value: 1}})
+	// 1.0 is 0x3f800000: words swapped, bytes swapped in each word
+	got := m.handlePDU(readPDU(fcReadInput, 0, 3))
+	want := []byte{fcReadInput, 6, 0x02, 0x01, 0x00, 0x00, 0x80, 0x3f}
+	if string(got) != string(want) {
+		t.Fatalf("read = %x, want %x", got, want)
+	}
+	if got := m.handlePDU(readPDU(fcReadHolding, 0, 2)); string(got) != string([]byte{fcReadHolding | 0x80, exIllegalFunction}) {
+		t.Fatalf("holding without calibration = %x", got)
+	}
+}
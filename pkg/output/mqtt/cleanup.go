@@ -0,0 +1,155 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+)
+
+// discoveryState is the content of the discovery state file.
+type discoveryState struct {
+	Topics []string `json:"topics"`
+}
+
+// CleanupDiscovery connects to the broker and clears discovery entities that
+// were published by a previous run but are no longer configured. If all is
+// true, every known entity (previous and current) is cleared. It returns the
+// cleared topics.
+func CleanupDiscovery(cfg Config, channels []config.ChannelConfig, all bool) ([]string, error) {
+	if cfg.DiscoveryStateFile == "" && !all {
+		return nil, fmt.Errorf("discovery_state_file is not configured")
+	}
+	enc, err := newPayloadEncoder(cfg, channels)
+	if err != nil {
+		return nil, fmt.Errorf("mqtt payload: %w", err)
+	}
+	var p publisher
+	if cfg.ProtocolVersion == 5 {
+		cm, err := connect5(cfg, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer disconnect5(cm)
+		p = v5Publisher{cm: cm}
+	} else {
+		client, err := connect(cfg, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer client.Disconnect(250)
+		p = v3Publisher{client}
+	}
+
+	current := discoveryTopics(buildDiscovery(cfg, channels, enc, stateTopic(cfg), ""))
+	if cfg.DiscoveryStateFile == "" {
+		return current, clearTopics(p, current)
+	}
+	return syncDiscoveryState(p, cfg.DiscoveryStateFile, current, all)
+}
+
+// syncDiscoveryState clears topics recorded in the state file that are not in
+// current (or all recorded and current topics if all is set) and records the
+// topics that remain published.
+func syncDiscoveryState(p publisher, path string, current []string, all bool) ([]string, error) {
+	prev, err := loadDiscoveryState(path)
+	if err != nil {
+		return nil, err
+	}
+	stale := staleTopics(prev, current)
+	keep := current
+	if all {
+		stale = staleTopics(append(prev, current...), nil)
+		keep = nil
+	}
+	if err := clearTopics(p, stale); err != nil {
+		return nil, err
+	}
+	return stale, saveDiscoveryState(path, keep)
+}
+
+// clearTopics publishes empty retained payloads, which removes the entities
+// from Home Assistant and the retained configs from the broker.
+func clearTopics(p publisher, topics []string) error {
+	for _, t := range topics {
+		if err := p.publish(t, []byte{}, true); err != nil {
+			return fmt.Errorf("clear %s: %w", t, err)
+		}
+	}
+	return nil
+}
+
+// loadDiscoveryState reads the topics recorded in the state file. A missing
+// file yields no topics.
+func loadDiscoveryState(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read discovery state: %w", err)
+	}
+	var st discoveryState
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, fmt.Errorf("parse discovery state: %w", err)
+	}
+	return st.Topics, nil
+}
+
+// saveDiscoveryState atomically writes the topics to the state file.
+func saveDiscoveryState(path string, topics []string) error {
+	if topics == nil {
+		topics = []string{}
+	}
+	b, err := json.MarshalIndent(discoveryState{Topics: topics}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".discovery-state-*")
+	if err != nil {
+		return fmt.Errorf("write discovery state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write discovery state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write discovery state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write discovery state: %w", err)
+	}
+	return nil
+}
+
+// staleTopics returns the sorted, de-duplicated topics of prev that are not in current.
+func staleTopics(prev, current []string) []string {
+	keep := make(map[string]bool, len(current))
+	for _, t := range current {
+		keep[t] = true
+	}
+	var out []string
+	for _, t := range prev {
+		if keep[t] {
+			continue
+		}
+		keep[t] = true
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// discoveryTopics returns the topics of the given discovery messages.
+func discoveryTopics(msgs []discoveryMessage) []string {
+	topics := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		topics = append(topics, m.Topic)
+	}
+	return topics
+}
@@ -0,0 +1,38 @@
+package mqtt
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStaleTopics(t *testing.T) {
+	prev := []string{"ha/sensor/b/config", "ha/sensor/a/config", "ha/sensor/c/config", "ha/sensor/a/config"}
+	current := []string{"ha/sensor/b/config"}
+	want := []string{"ha/sensor/a/config", "ha/sensor/c/config"}
+	if got := staleTopics(prev, current); !reflect.DeepEqual(got, want) {
+		t.Fatalf("staleTopics = %v; want %v", got, want)
+	}
+	if got := staleTopics(nil, current); len(got) != 0 {
+		t.Fatalf("staleTopics without state = %v; want none", got)
+	}
+}
+
+func TestDiscoveryStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "discovery.json")
+	topics, err := loadDiscoveryState(path)
+	if err != nil || topics != nil {
+		t.Fatalf("missing state: topics=%v err=%v", topics, err)
+	}
+	want := []string{"ha/sensor/a/config", "ha/sensor/b/config"}
+	if err := saveDiscoveryState(path, want); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, err := loadDiscoveryState(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("loaded %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+package mqtt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Config holds the settings of the mqtt output.
+type Config struct {
+	Server   string `json:"server"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	ClientID string `json:"client_id"`
+	// StateTopic is the MQTT topic where the sensor state/value is published.
+	StateTopic string `json:"state_topic"`
+	// DiscoveryTopic is the full topic to publish Home Assistant discovery payload to
+	// (for example: `homeassistant/sensor/machine_battery/config`). If empty, discovery is not published.
+	DiscoveryTopic string `json:"discovery_topic,omitempty"`
+	// Optional discovery payload fields for Home Assistant
+	DiscoveryName     string `json:"discovery_name,omitempty"`
+	DiscoveryUniqueID string `json:"discovery_unique_id,omitempty"`
+	// DiscoveryDevice customizes the Home Assistant device block shared by all entities.
+	DiscoveryDevice *DiscoveryDeviceConfig `json:"discovery_device,omitempty"`
+	// DiscoveryExpireAfter sets expire_after (seconds) on discovered entities. 0 disables it.
+	DiscoveryExpireAfter int `json:"discovery_expire_after,omitempty"`
+	// DiscoveryRawTopic is the discovery topic (optionally with %d) for diagnostic
+	// entities exposing the raw ADC value. If empty, raw entities are not published.
+	DiscoveryRawTopic string `json:"discovery_raw_topic,omitempty"`
+	// DiscoveryRawEntityCategory is the entity_category of raw entities. Default: "diagnostic".
+	DiscoveryRawEntityCategory string `json:"discovery_raw_entity_category,omitempty"`
+	// DiscoveryStateFile is the path of a file remembering the discovery topics
+	// published by this output. When set, entities from a previous run that are
+	// no longer configured are cleared with empty retained payloads.
+	DiscoveryStateFile string `json:"discovery_state_file,omitempty"`
+	// DiscoveryValueTemplate overrides the value_template sent in the discovery payload.
+	// If empty, a template matching the payload format is used.
+	DiscoveryValueTemplate string `json:"discovery_value_template,omitempty"`
+	// PayloadFormat selects how state payloads are encoded: "json" (default),
+	// "value" (plain number) or "template" (Go text/template over the reading).
+	PayloadFormat string `json:"payload_format,omitempty"`
+	// PayloadFields lists the fields included in "json" payloads. Supported:
+	// voltage, raw, channel, name, unit, timestamp, device. Default: voltage, raw.
+	PayloadFields []string `json:"payload_fields,omitempty"`
+	// PayloadPrecision is the number of decimals used by the "value" format.
+	// If nil, the shortest exact representation is used.
+	PayloadPrecision *int `json:"payload_precision,omitempty"`
+	// PayloadTemplate is the text/template rendered for each reading when
+	// PayloadFormat is "template".
+	PayloadTemplate string `json:"payload_template,omitempty"`
+	// ProtocolVersion selects the MQTT protocol: 3 (3.1), 4 (3.1.1) or 5. Default: 3.1.1.
+	ProtocolVersion int `json:"protocol_version,omitempty"`
+	// MessageExpiry is the MQTT 5 message expiry interval (seconds) of state messages. 0 disables it.
+	MessageExpiry int `json:"message_expiry,omitempty"`
+	// ContentType is the MQTT 5 content type of state messages. Default depends on the payload format.
+	ContentType string `json:"content_type,omitempty"`
+	// UserProperties are extra MQTT 5 user properties added to state messages
+	// (device and unit properties are always sent).
+	UserProperties map[string]string `json:"user_properties,omitempty"`
+	// TopicAliasMaximum is the number of MQTT 5 topic aliases used for state
+	// topics (bounded by the broker limit). 0 disables topic aliases.
+	TopicAliasMaximum int `json:"topic_alias_maximum,omitempty"`
+	// CommandTopic is subscribed for remote control commands. If empty, commands are disabled.
+	CommandTopic string `json:"command_topic,omitempty"`
+	// ResponseTopic receives command responses. Default: CommandTopic + "/response".
+	ResponseTopic string `json:"response_topic,omitempty"`
+	// Combined publishes a single JSON document per snapshot on StateTopic with
+	// every channel keyed by name (or index) and a shared timestamp.
+	Combined bool `json:"combined,omitempty"`
+}
+
+// DiscoveryDeviceConfig holds the Home Assistant device registry fields. Empty
+// fields fall back to defaults derived from the MQTT settings.
+type DiscoveryDeviceConfig struct {
+	Identifiers  []string `json:"identifiers,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	Model        string   `json:"model,omitempty"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	SWVersion    string   `json:"sw_version,omitempty"`
+}
+
+// MQTT payload formats.
+const (
+	PayloadFormatJSON     = "json"
+	PayloadFormatValue    = "value"
+	PayloadFormatTemplate = "template"
+)
+
+// PayloadFieldNames lists the fields accepted in Config.PayloadFields.
+var PayloadFieldNames = []string{"voltage", "raw", "channel", "name", "unit", "timestamp", "device"}
+
+// Validate checks the MQTT payload settings, parsing the payload template if set.
+func (c Config) Validate() error {
+	switch strings.ToLower(c.PayloadFormat) {
+	case "", PayloadFormatJSON:
+		for _, f := range c.PayloadFields {
+			if !slices.Contains(PayloadFieldNames, f) {
+				return fmt.Errorf("invalid payload field %q; allowed: %v", f, PayloadFieldNames)
+			}
+		}
+	case PayloadFormatValue:
+		if c.PayloadPrecision != nil && (*c.PayloadPrecision < 0 || *c.PayloadPrecision > 15) {
+			return fmt.Errorf("invalid payload_precision %d; allowed: 0..15", *c.PayloadPrecision)
+		}
+	case PayloadFormatTemplate:
+		if c.PayloadTemplate == "" {
+			return fmt.Errorf("payload_template is required when payload_format is %q", PayloadFormatTemplate)
+		}
+		if _, err := template.New("payload").Parse(c.PayloadTemplate); err != nil {
+			return fmt.Errorf("payload_template: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid payload_format %q; allowed: json, value, template", c.PayloadFormat)
+	}
+	switch c.ProtocolVersion {
+	case 0, 3, 4:
+		if c.MessageExpiry != 0 || c.ContentType != "" || len(c.UserProperties) > 0 || c.TopicAliasMaximum != 0 {
+			return fmt.Errorf("message_expiry, content_type, user_properties and topic_alias_maximum require protocol_version 5")
+		}
+	case 5:
+		if c.MessageExpiry < 0 {
+			return fmt.Errorf("invalid message_expiry %d", c.MessageExpiry)
+		}
+		if c.TopicAliasMaximum < 0 || c.TopicAliasMaximum > 65535 {
+			return fmt.Errorf("invalid topic_alias_maximum %d; allowed: 0..65535", c.TopicAliasMaximum)
+		}
+	default:
+		return fmt.Errorf("invalid protocol_version %d; allowed: 3, 4, 5", c.ProtocolVersion)
+	}
+	if c.DiscoveryRawTopic != "" {
+		if f := strings.ToLower(c.PayloadFormat); f != "" && f != PayloadFormatJSON {
+			return fmt.Errorf("discovery_raw_topic requires payload_format %q", PayloadFormatJSON)
+		}
+		if len(c.PayloadFields) > 0 && !slices.Contains(c.PayloadFields, "raw") {
+			return fmt.Errorf("discovery_raw_topic requires the raw payload field")
+		}
+		// one raw entity per channel needs a topic per channel, and a single
+		// one a topic without a placeholder
+		if perChannel := strings.Contains(c.DiscoveryTopic, "%d"); perChannel != strings.Contains(c.DiscoveryRawTopic, "%d") {
+			if perChannel {
+				return fmt.Errorf("discovery_raw_topic requires %%d when discovery_topic has %%d")
+			}
+			return fmt.Errorf("discovery_raw_topic has %%d but discovery_topic does not")
+		}
+	}
+	if c.Combined {
+		if f := strings.ToLower(c.PayloadFormat); f != "" && f != PayloadFormatJSON {
+			return fmt.Errorf("combined requires payload_format %q", PayloadFormatJSON)
+		}
+		if strings.Contains(c.StateTopic, "%d") {
+			return fmt.Errorf("combined requires a state_topic without %%d")
+		}
+		if c.DiscoveryTopic != "" && !strings.Contains(c.DiscoveryTopic, "%d") {
+			return fmt.Errorf("combined requires a per-channel discovery_topic with %%d")
+		}
+	}
+	// the default value_template of JSON payloads selects the voltage field
+	if f := strings.ToLower(c.PayloadFormat); c.DiscoveryTopic != "" && c.DiscoveryValueTemplate == "" && (f == "" || f == PayloadFormatJSON) &&
+		len(c.PayloadFields) > 0 && !slices.Contains(c.PayloadFields, "voltage") {
+		return fmt.Errorf("discovery with payload_format %q requires the voltage payload field or discovery_value_template", PayloadFormatJSON)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package mqtt
+
+import "testing"
+
+func TestConfigValidate(t *testing.T) {
+	prec := 2
+	badPrec := 42
+	tests := []struct {
+		name string
+		cfg  Config
+		ok   bool
+	}{
+		{"default", Config{}, true},
+		{"json fields", Config{PayloadFormat: "json", PayloadFields: []string{"voltage", "timestamp", "unit"}}, true},
+		{"json bad field", Config{PayloadFormat: "json", PayloadFields: []string{"volts"}}, false},
+		{"value", Config{PayloadFormat: "value", PayloadPrecision: &prec}, true},
+		{"value bad precision", Config{PayloadFormat: "value", PayloadPrecision: &badPrec}, false},
+		{"template", Config{PayloadFormat: "template", PayloadTemplate: "{{ .Value }}"}, true},
+		{"template missing", Config{PayloadFormat: "template"}, false},
+		{"template invalid", Config{PayloadFormat: "template", PayloadTemplate: "{{ .Value "}, false},
+		{"unknown format", Config{PayloadFormat: "xml"}, false},
+		{"raw per channel", Config{DiscoveryTopic: "homeassistant/sensor/ads_ch%d/config", DiscoveryRawTopic: "homeassistant/sensor/ads_ch%d_raw/config"}, true},
+		{"raw single", Config{DiscoveryTopic: "homeassistant/sensor/ads/config", DiscoveryRawTopic: "homeassistant/sensor/ads_raw/config"}, true},
+		{"raw without %d", Config{DiscoveryTopic: "homeassistant/sensor/ads_ch%d/config", DiscoveryRawTopic: "homeassistant/sensor/ads_raw/config"}, false},
+		{"discovery without voltage", Config{DiscoveryTopic: "homeassistant/sensor/ads_ch%d/config", PayloadFields: []string{"raw"}}, false},
+		{"discovery template without voltage", Config{DiscoveryTopic: "homeassistant/sensor/ads_ch%d/config", PayloadFields: []string{"raw"}, DiscoveryValueTemplate: "{{ value_json.raw }}"}, true},
+		{"value discovery", Config{DiscoveryTopic: "homeassistant/sensor/ads_ch%d/config", PayloadFormat: "value"}, true},
+		{"raw with %d only", Config{DiscoveryTopic: "homeassistant/sensor/ads/config", DiscoveryRawTopic: "homeassistant/sensor/ads_ch%d_raw/config"}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err == nil) != tt.ok {
+			t.Fatalf("%s: ok=%v err=%v", tt.name, tt.ok, err)
+		}
+	}
+}
+
+func TestConfigValidateCombined(t *testing.T) {
+	tests := []struct {
+		cfg Config
+		ok  bool
+	}{
+		{Config{Combined: true, StateTopic: "ads1115/state", DiscoveryTopic: "homeassistant/sensor/ads_ch%d/config"}, true},
+		{Config{Combined: true, StateTopic: "ads1115/channel/%d"}, false},
+		{Config{Combined: true, PayloadFormat: "value"}, false},
+		{Config{Combined: true, DiscoveryTopic: "homeassistant/sensor/ads/config"}, false},
+		{Config{Combined: true, DiscoveryTopic: "homeassistant/sensor/ads_ch%d/config", PayloadFields: []string{"raw"}}, false},
+		{Config{Combined: true, DiscoveryTopic: "homeassistant/sensor/ads_ch%d/config", PayloadFields: []string{"raw"}, DiscoveryValueTemplate: "{{ value_json.channels['0'].raw }}"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err == nil) != tt.ok {
+			t.Fatalf("%+v: ok=%v err=%v", tt.cfg, tt.ok, err)
+		}
+	}
+}
+
+func TestConfigValidateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		cfg Config
+		ok  bool
+	}{
+		{Config{ProtocolVersion: 4}, true},
+		{Config{ProtocolVersion: 5, MessageExpiry: 60, ContentType: "application/json", TopicAliasMaximum: 10}, true},
+		{Config{ProtocolVersion: 6}, false},
+		{Config{MessageExpiry: 60}, false},
+		{Config{ProtocolVersion: 5, MessageExpiry: -1}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err == nil) != tt.ok {
+			t.Fatalf("%+v: ok=%v err=%v", tt.cfg, tt.ok, err)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package mqtt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+)
+
+const (
+	// discovery payload keys/values
+	keyName                      = "name"
+	keyStateTopic                = "state_topic"
+	keyUnitOfMeasurement         = "unit_of_measurement"
+	keyDeviceClass               = "device_class"
+	keyStateClass                = "state_class"
+	keyValueTemplate             = "value_template"
+	keyJSONAttributesTopic       = "json_attributes_topic"
+	keyUniqueID                  = "unique_id"
+	keyDevice                    = "device"
+	keySuggestedDisplayPrecision = "suggested_display_precision"
+	keyExpireAfter               = "expire_after"
+	keyEntityCategory            = "entity_category"
+	unitVolts                    = "V"
+	deviceClassVoltage           = "voltage"
+	stateClassMeasurement        = "measurement"
+	entityCategoryDiagnostic     = "diagnostic"
+	valueTemplateVoltage         = "{{ value_json.voltage }}"
+	valueTemplateRaw             = "{{ value_json.raw }}"
+	valueTemplatePlain           = "{{ value }}"
+	deviceModel                  = "ADS1115"
+	deviceManufacturer           = "Texas Instruments"
+)
+
+// discoveryMessage is a retained Home Assistant discovery config and its topic.
+type discoveryMessage struct {
+	Topic   string
+	Payload map[string]interface{}
+}
+
+// buildDiscovery returns the discovery messages for every configured entity:
+// one per enabled channel when the discovery topic contains %d (or a single one
+// otherwise), plus the optional raw diagnostic entities.
+func buildDiscovery(cfg Config, channels []config.ChannelConfig, enc *payloadEncoder, stateTopic, version string) []discoveryMessage {
+	if cfg.DiscoveryTopic == "" {
+		return nil
+	}
+	device := discoveryDevice(cfg, version)
+	var msgs []discoveryMessage
+	if !strings.Contains(cfg.DiscoveryTopic, "%d") {
+		name := discoveryName(cfg, nil)
+		uniqueID := discoveryUniqueID(cfg, nil)
+		payload := baseDiscoveryPayload(name, stateTopic, uniqueID)
+		applyValueTemplate(payload, cfg, enc, enc.ValueTemplate())
+		applyCommon(payload, cfg, device)
+		msgs = append(msgs, discoveryMessage{Topic: cfg.DiscoveryTopic, Payload: payload})
+		if cfg.DiscoveryRawTopic != "" {
+			raw := rawDiscoveryPayload(cfg, name, stateTopic, uniqueID, valueTemplateRaw)
+			applyCommon(raw, cfg, device)
+			msgs = append(msgs, discoveryMessage{Topic: cfg.DiscoveryRawTopic, Payload: raw})
+		}
+		return msgs
+	}
+	for _, ch := range channels {
+		if !ch.Enabled {
+			continue
+		}
+		name := discoveryName(cfg, &ch)
+		uniqueID := discoveryUniqueID(cfg, &ch)
+		chStateTopic := formatStateTopic(cfg.StateTopic, ch.Channel)
+		valueTemplate := enc.ValueTemplate()
+		rawTemplate := valueTemplateRaw
+		if cfg.Combined {
+			chStateTopic = stateTopic
+			valueTemplate = enc.SnapshotValueTemplate(ch.Channel)
+			rawTemplate = fmt.Sprintf("{{ value_json.channels['%s'].raw }}", enc.ChannelKey(ch.Channel))
+		}
+		payload := baseDiscoveryPayload(name, chStateTopic, uniqueID)
+		applyChannel(payload, ch)
+		applyValueTemplate(payload, cfg, enc, valueTemplate)
+		applyCommon(payload, cfg, device)
+		msgs = append(msgs, discoveryMessage{Topic: fmt.Sprintf(cfg.DiscoveryTopic, ch.Channel), Payload: payload})
+		if cfg.DiscoveryRawTopic != "" {
+			raw := rawDiscoveryPayload(cfg, name, chStateTopic, uniqueID, rawTemplate)
+			applyCommon(raw, cfg, device)
+			msgs = append(msgs, discoveryMessage{Topic: formatTopic(cfg.DiscoveryRawTopic, ch.Channel), Payload: raw})
+		}
+	}
+	return msgs
+}
+
+// helper: build a human-friendly discovery name; if ch != nil append channel
+func discoveryName(cfg Config, ch *config.ChannelConfig) string {
+	name := cfg.DiscoveryName
+	if name == "" {
+		name = fmt.Sprintf("ADS1115 %s", cfg.ClientID)
+	}
+	if ch != nil {
+		if ch.Name != "" {
+			return fmt.Sprintf("%s %s", name, ch.Name)
+		}
+		name = fmt.Sprintf("%s ch%d", name, ch.Channel)
+	}
+	return name
+}
+
+// helper: build a unique id for discovery; if ch != nil append channel
+func discoveryUniqueID(cfg Config, ch *config.ChannelConfig) string {
+	uid := cfg.DiscoveryUniqueID
+	if uid == "" {
+		uid = cfg.ClientID
+	}
+	if uid != "" && ch != nil {
+		uid = fmt.Sprintf("%s_%d", uid, ch.Channel)
+	}
+	return uid
+}
+
+// helper: base discovery payload map common to all entries
+func baseDiscoveryPayload(name, stateTopic, uniqueID string) map[string]interface{} {
+	payload := map[string]interface{}{
+		keyName:                name,
+		keyStateTopic:          stateTopic,
+		keyUnitOfMeasurement:   unitVolts,
+		keyDeviceClass:         deviceClassVoltage,
+		keyStateClass:          stateClassMeasurement,
+		keyValueTemplate:       valueTemplateVoltage,
+		keyJSONAttributesTopic: stateTopic,
+	}
+	if uniqueID != "" {
+		payload[keyUniqueID] = uniqueID
+	}
+	return payload
+}
+
+// helper: raw diagnostic entity payload derived from the channel entity
+func rawDiscoveryPayload(cfg Config, name, stateTopic, uniqueID, valueTemplate string) map[string]interface{} {
+	category := cfg.DiscoveryRawEntityCategory
+	if category == "" {
+		category = entityCategoryDiagnostic
+	}
+	payload := map[string]interface{}{
+		keyName:           name + " raw",
+		keyStateTopic:     stateTopic,
+		keyStateClass:     stateClassMeasurement,
+		keyValueTemplate:  valueTemplate,
+		keyEntityCategory: category,
+	}
+	if uniqueID != "" {
+		payload[keyUniqueID] = uniqueID + "_raw"
+	}
+	return payload
+}
+
+// helper: apply per-channel unit, device class, state class and precision
+func applyChannel(payload map[string]interface{}, ch config.ChannelConfig) {
+	unit := ch.UnitOrDefault()
+	payload[keyUnitOfMeasurement] = unit
+	switch {
+	case ch.DeviceClass != "":
+		payload[keyDeviceClass] = ch.DeviceClass
+	case unit != unitVolts:
+		delete(payload, keyDeviceClass)
+	}
+	if ch.StateClass != "" {
+		payload[keyStateClass] = ch.StateClass
+	}
+	if ch.Precision != nil {
+		payload[keySuggestedDisplayPrecision] = *ch.Precision
+	}
+}
+
+// helper: set the value template matching the payload format (or the configured override)
+func applyValueTemplate(payload map[string]interface{}, cfg Config, enc *payloadEncoder, valueTemplate string) {
+	payload[keyValueTemplate] = valueTemplate
+	if cfg.DiscoveryValueTemplate != "" {
+		payload[keyValueTemplate] = cfg.DiscoveryValueTemplate
+	}
+	if !enc.IsJSON() || cfg.Combined {
+		delete(payload, keyJSONAttributesTopic)
+	}
+}
+
+// helper: apply the device block and expire_after shared by all entities
+func applyCommon(payload map[string]interface{}, cfg Config, device map[string]interface{}) {
+	payload[keyDevice] = device
+	if cfg.DiscoveryExpireAfter > 0 {
+		payload[keyExpireAfter] = cfg.DiscoveryExpireAfter
+	}
+}
+
+// discoveryDevice builds the Home Assistant device registry block so all
+// entities are grouped under one ADS1115 device.
+func discoveryDevice(cfg Config, version string) map[string]interface{} {
+	var dc DiscoveryDeviceConfig
+	if cfg.DiscoveryDevice != nil {
+		dc = *cfg.DiscoveryDevice
+	}
+	ids := dc.Identifiers
+	if len(ids) == 0 {
+		id := discoveryUniqueID(cfg, nil)
+		if id == "" {
+			id = DefaultClientID
+		}
+		ids = []string{id}
+	}
+	name := dc.Name
+	if name == "" {
+		name = discoveryName(cfg, nil)
+	}
+	model := dc.Model
+	if model == "" {
+		model = deviceModel
+	}
+	manufacturer := dc.Manufacturer
+	if manufacturer == "" {
+		manufacturer = deviceManufacturer
+	}
+	swVersion := dc.SWVersion
+	if swVersion == "" {
+		swVersion = version
+	}
+	device := map[string]interface{}{
+		"identifiers":  ids,
+		"name":         name,
+		"model":        model,
+		"manufacturer": manufacturer,
+	}
+	if swVersion != "" {
+		device["sw_version"] = swVersion
+	}
+	return device
+}
+
+// helper: format a topic for a channel when it contains a formatter
+func formatTopic(topic string, ch int) string {
+	if strings.Contains(topic, "%d") {
+		return fmt.Sprintf(topic, ch)
+	}
+	return topic
+}
@@ -0,0 +1,61 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+)
+
+func TestBuildDiscoveryDeviceAndChannels(t *testing.T) {
+	prec := 2
+	cfg := Config{
+		ClientID:             "ads1115-client",
+		StateTopic:           "ads1115/channel/%d",
+		DiscoveryTopic:       "homeassistant/sensor/battery_ch%d/config",
+		DiscoveryRawTopic:    "homeassistant/sensor/battery_ch%d_raw/config",
+		DiscoveryName:        "Battery",
+		DiscoveryUniqueID:    "battery",
+		DiscoveryExpireAfter: 60,
+	}
+	channels := []config.ChannelConfig{
+		{Channel: 0, Enabled: true, Precision: &prec},
+		{Channel: 1, Enabled: true, Name: "current", Unit: "A", DeviceClass: "current"},
+		{Channel: 2, Enabled: false},
+	}
+	enc, err := newPayloadEncoder(cfg, channels)
+	if err != nil {
+		t.Fatalf("newPayloadEncoder: %v", err)
+	}
+	msgs := buildDiscovery(cfg, channels, enc, cfg.StateTopic, "v1.2.3")
+	if len(msgs) != 4 {
+		t.Fatalf("messages: got %d want 4", len(msgs))
+	}
+
+	got, _ := json.Marshal(msgs[0].Payload)
+	want := `{"device":{"identifiers":["battery"],"manufacturer":"Texas Instruments","model":"ADS1115","name":"Battery","sw_version":"v1.2.3"},"device_class":"voltage","expire_after":60,"json_attributes_topic":"ads1115/channel/0","name":"Battery ch0","state_class":"measurement","state_topic":"ads1115/channel/0","suggested_display_precision":2,"unique_id":"battery_0","unit_of_measurement":"V","value_template":"{{ value_json.voltage }}"}`
+	if msgs[0].Topic != "homeassistant/sensor/battery_ch0/config" || string(got) != want {
+		t.Fatalf("channel 0 discovery:\n got: %s %s\nwant: %s", msgs[0].Topic, got, want)
+	}
+
+	raw := msgs[1].Payload
+	if msgs[1].Topic != "homeassistant/sensor/battery_ch0_raw/config" || raw[keyEntityCategory] != entityCategoryDiagnostic || raw[keyValueTemplate] != valueTemplateRaw || raw[keyUniqueID] != "battery_0_raw" {
+		t.Fatalf("raw discovery: %s %v", msgs[1].Topic, raw)
+	}
+
+	ch1 := msgs[2].Payload
+	if ch1[keyUnitOfMeasurement] != "A" || ch1[keyDeviceClass] != "current" || ch1[keyName] != "Battery current" {
+		t.Fatalf("channel 1 discovery: %v", ch1)
+	}
+}
+
+func TestBuildDiscoveryDisabled(t *testing.T) {
+	cfg := Config{ClientID: "c"}
+	enc, err := newPayloadEncoder(cfg, nil)
+	if err != nil {
+		t.Fatalf("newPayloadEncoder: %v", err)
+	}
+	if msgs := buildDiscovery(cfg, nil, enc, "ads1115", ""); len(msgs) != 0 {
+		t.Fatalf("expected no discovery messages, got %d", len(msgs))
+	}
+}
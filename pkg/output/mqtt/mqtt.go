@@ -3,10 +3,13 @@ package mqtt
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"strings"
+	"sync"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/control"
 	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
 	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
 )
@@ -17,29 +20,93 @@ const (
 	DefaultClientID    = "ads1115-client"
 	DefaultStateTopic  = "ads1115"
 	perChannelTopicFmt = "ads1115/channel/%d"
-	// discovery payload keys/values
-	keyName                = "name"
-	keyStateTopic          = "state_topic"
-	keyUnitOfMeasurement   = "unit_of_measurement"
-	keyDeviceClass         = "device_class"
-	keyStateClass          = "state_class"
-	keyValueTemplate       = "value_template"
-	keyJSONAttributesTopic = "json_attributes_topic"
-	keyUniqueID            = "unique_id"
-	unitVolts              = "V"
-	deviceClassVoltage     = "voltage"
-	stateClassMeasurement  = "measurement"
-	valueTemplateVoltage   = "{{ value_json.voltage }}"
+	// responseTopicSuffix is appended to the command topic when no response topic is set
+	responseTopicSuffix = "/response"
 )
 
 type MQTTOutput struct {
-	client         mqtt.Client
-	stateTopic     string
-	discoveryTopic string
+	client        mqtt.Client
+	stateTopic    string
+	payload       *payloadEncoder
+	combined      bool
+	commandTopic  string
+	responseTopic string
+
+	mu      sync.Mutex
+	handler control.Handler
+}
+
+func init() {
+	output.Register("mqtt", output.Registration{
+		Options: func() any {
+			return &Config{Server: DefaultServer, ClientID: DefaultClientID, StateTopic: DefaultStateTopic}
+		},
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewMQTT(*opts.(*Config), env.Channels, env.Version)
+		},
+	})
 }
 
-func NewMQTT(cfg config.MQTTConfig, channels []config.ChannelConfig) (output.Output, error) {
+// NewMQTT connects to the broker and publishes discovery payloads. version is
+// reported as the Home Assistant device sw_version.
+// When cfg.ProtocolVersion is 5 an MQTT 5 client is used.
+func NewMQTT(cfg Config, channels []config.ChannelConfig, version string) (output.Output, error) {
+	enc, err := newPayloadEncoder(cfg, channels)
+	if err != nil {
+		return nil, fmt.Errorf("mqtt payload: %w", err)
+	}
+	if cfg.ProtocolVersion == 5 {
+		m5, err := newMQTT5(cfg, channels, enc, version)
+		if err != nil {
+			return nil, err
+		}
+		return m5, nil
+	}
+	st := stateTopic(cfg)
+	m := &MQTTOutput{stateTopic: st, payload: enc, combined: cfg.Combined, commandTopic: cfg.CommandTopic, responseTopic: cfg.ResponseTopic}
+	if m.responseTopic == "" && m.commandTopic != "" {
+		m.responseTopic = m.commandTopic + responseTopicSuffix
+	}
+	client, err := connect(cfg, m.onConnect)
+	if err != nil {
+		return nil, err
+	}
+	m.client = client
+
+	if err := publishDiscovery(v3Publisher{client}, cfg, channels, enc, st, version); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// publishDiscovery publishes the Home Assistant discovery payload(s), if
+// requested, and clears entities of a previous run that are no longer configured.
+func publishDiscovery(p publisher, cfg Config, channels []config.ChannelConfig, enc *payloadEncoder, stateTopic, version string) error {
+	msgs := buildDiscovery(cfg, channels, enc, stateTopic, version)
+	for _, d := range msgs {
+		if err := publishJSON(p, d.Topic, true, d.Payload); err != nil {
+			return fmt.Errorf("mqtt discovery publish: %w", err)
+		}
+	}
+	if cfg.DiscoveryStateFile != "" {
+		if _, err := syncDiscoveryState(p, cfg.DiscoveryStateFile, discoveryTopics(msgs), false); err != nil {
+			return fmt.Errorf("mqtt discovery cleanup: %w", err)
+		}
+	}
+	return nil
+}
+
+// connect creates an MQTT client and connects it to the configured broker.
+// onConnect (optional) runs after every (re)connection.
+func connect(cfg Config, onConnect mqtt.OnConnectHandler) (mqtt.Client, error) {
 	opts := mqtt.NewClientOptions().AddBroker(cfg.Server).SetClientID(cfg.ClientID)
+	if cfg.ProtocolVersion != 0 {
+		opts.SetProtocolVersion(uint(cfg.ProtocolVersion))
+	}
+	if onConnect != nil {
+		opts.SetOnConnectHandler(onConnect)
+	}
 	if cfg.Username != "" {
 		opts.SetUsername(cfg.Username)
 	}
@@ -51,41 +118,25 @@ func NewMQTT(cfg config.MQTTConfig, channels []config.ChannelConfig) (output.Out
 	if token.Wait() && token.Error() != nil {
 		return nil, fmt.Errorf("mqtt connect: %w", token.Error())
 	}
+	return client, nil
+}
 
-	st := cfg.StateTopic
-	m := &MQTTOutput{client: client, stateTopic: st, discoveryTopic: cfg.DiscoveryTopic}
-
-	// Publish Home Assistant discovery payload(s) if requested
-	if m.discoveryTopic != "" {
-		// per-channel discovery when discoveryTopic contains a formatter
-		if strings.Contains(m.discoveryTopic, "%d") {
-			for _, ch := range channels {
-				if !ch.Enabled {
-					continue
-				}
-				dTopic := fmt.Sprintf(m.discoveryTopic, ch.Channel)
-				stateTopic := formatStateTopic(cfg.StateTopic, ch.Channel)
-				name := discoveryName(cfg, &ch)
-				uniqueID := discoveryUniqueID(cfg, &ch)
-				payload := baseDiscoveryPayload(name, stateTopic, uniqueID)
-				if err := publishJSON(client, dTopic, true, payload); err != nil {
-					return nil, fmt.Errorf("mqtt discovery publish: %w", err)
-				}
-			}
-		} else {
-			name := discoveryName(cfg, nil)
-			uniqueID := discoveryUniqueID(cfg, nil)
-			payload := baseDiscoveryPayload(name, m.stateTopic, uniqueID)
-			if err := publishJSON(client, m.discoveryTopic, true, payload); err != nil {
-				return nil, fmt.Errorf("mqtt discovery publish: %w", err)
-			}
-		}
+// stateTopic returns the configured state topic, defaulting it for combined payloads.
+func stateTopic(cfg Config) string {
+	if cfg.Combined && cfg.StateTopic == "" {
+		return DefaultStateTopic
 	}
-
-	return m, nil
+	return cfg.StateTopic
 }
 
 func (m *MQTTOutput) Publish(readings []sensor.Reading) error {
+	if m.combined {
+		b, err := m.payload.EncodeSnapshot(readings)
+		if err != nil {
+			return err
+		}
+		return m.PublishRaw(m.stateTopic, b, false)
+	}
 	for _, r := range readings {
 		// determine topic: if stateTopic contains a %d formatter use it for channel
 		topic := m.stateTopic
@@ -97,9 +148,8 @@ func (m *MQTTOutput) Publish(readings []sensor.Reading) error {
 			topic = fmt.Sprintf(perChannelTopicFmt, r.Channel)
 		}
 
-		// publish payload including averaged value and raw (raw is sent as integer)
-		payload := map[string]interface{}{"voltage": r.Value, "raw": r.Raw}
-		b, err := json.Marshal(payload)
+		// publish payload in the configured format (default: JSON with voltage and raw)
+		b, err := m.payload.Encode(r)
 		if err != nil {
 			return err
 		}
@@ -112,6 +162,61 @@ func (m *MQTTOutput) Publish(readings []sensor.Reading) error {
 	return nil
 }
 
+// SetCommandHandler subscribes to the command topic (if configured) and
+// dispatches received commands to h, replying on the response topic.
+func (m *MQTTOutput) SetCommandHandler(h control.Handler) error {
+	if m.commandTopic == "" {
+		return nil
+	}
+	m.mu.Lock()
+	m.handler = h
+	m.mu.Unlock()
+	return m.subscribeCommands(m.client)
+}
+
+// onConnect restores the command subscription after a reconnection.
+func (m *MQTTOutput) onConnect(client mqtt.Client) {
+	m.mu.Lock()
+	h := m.handler
+	m.mu.Unlock()
+	if h == nil {
+		return
+	}
+	if err := m.subscribeCommands(client); err != nil {
+		log.Printf("mqtt: resubscribe %s: %v", m.commandTopic, err)
+	}
+}
+
+func (m *MQTTOutput) subscribeCommands(client mqtt.Client) error {
+	token := client.Subscribe(m.commandTopic, 1, m.handleCommand)
+	token.Wait()
+	if token.Error() != nil {
+		return fmt.Errorf("mqtt subscribe %s: %w", m.commandTopic, token.Error())
+	}
+	return nil
+}
+
+// handleCommand decodes a command message, runs it and publishes the response.
+func (m *MQTTOutput) handleCommand(client mqtt.Client, msg mqtt.Message) {
+	m.mu.Lock()
+	h := m.handler
+	m.mu.Unlock()
+	var cmd control.Command
+	var resp control.Response
+	if err := json.Unmarshal(msg.Payload(), &cmd); err != nil {
+		resp = control.Errorf(cmd, "invalid command: %v", err)
+	} else {
+		resp = h.Handle(cmd)
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("mqtt: command response: %v", err)
+		return
+	}
+	// do not wait for the token: blocking inside a message handler stalls the client
+	client.Publish(m.responseTopic, 1, false, b)
+}
+
 func (m *MQTTOutput) Close() error {
 	if m.client != nil {
 		m.client.Disconnect(250)
@@ -141,54 +246,26 @@ func formatStateTopic(base string, ch int) string {
 	return fmt.Sprintf("ads1115/channel/%d", ch)
 }
 
-// helper: build a human-friendly discovery name; if ch != nil append channel
-func discoveryName(cfg config.MQTTConfig, ch *config.ChannelConfig) string {
-	name := cfg.DiscoveryName
-	if name == "" {
-		name = fmt.Sprintf("ADS1115 %s", cfg.ClientID)
-	}
-	if ch != nil {
-		name = fmt.Sprintf("%s ch%d", name, ch.Channel)
-	}
-	return name
+// publisher sends a message and waits until it is handed to the broker. It
+// lets discovery and cleanup work with both the MQTT 3.1.1 and 5 clients.
+type publisher interface {
+	publish(topic string, payload []byte, retained bool) error
 }
 
-// helper: build a unique id for discovery; if ch != nil append channel
-func discoveryUniqueID(cfg config.MQTTConfig, ch *config.ChannelConfig) string {
-	uid := cfg.DiscoveryUniqueID
-	if uid == "" {
-		uid = cfg.ClientID
-	}
-	if uid != "" && ch != nil {
-		uid = fmt.Sprintf("%s_%d", uid, ch.Channel)
-	}
-	return uid
-}
+// v3Publisher adapts a paho.mqtt.golang client to publisher.
+type v3Publisher struct{ client mqtt.Client }
 
-// helper: base discovery payload map common to all entries
-func baseDiscoveryPayload(name, stateTopic, uniqueID string) map[string]interface{} {
-	payload := map[string]interface{}{
-		keyName:                name,
-		keyStateTopic:          stateTopic,
-		keyUnitOfMeasurement:   unitVolts,
-		keyDeviceClass:         deviceClassVoltage,
-		keyStateClass:          stateClassMeasurement,
-		keyValueTemplate:       valueTemplateVoltage,
-		keyJSONAttributesTopic: stateTopic,
-	}
-	if uniqueID != "" {
-		payload[keyUniqueID] = uniqueID
-	}
-	return payload
+func (p v3Publisher) publish(topic string, payload []byte, retained bool) error {
+	token := p.client.Publish(topic, 0, retained, payload)
+	token.Wait()
+	return token.Error()
 }
 
 // helper: marshal and publish JSON payload
-func publishJSON(client mqtt.Client, topic string, retained bool, payload map[string]interface{}) error {
+func publishJSON(p publisher, topic string, retained bool, payload map[string]interface{}) error {
 	b, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
-	token := client.Publish(topic, 0, retained, b)
-	token.Wait()
-	return token.Error()
+	return p.publish(topic, b, retained)
 }
@@ -0,0 +1,341 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/control"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	// timeout for MQTT 5 connection and publish operations
+	timeout5 = 10 * time.Second
+	// user property keys sent with MQTT 5 state messages
+	userPropertyDevice = "device"
+	userPropertyUnit   = "unit"
+	contentTypeJSON    = "application/json"
+	contentTypeText    = "text/plain"
+)
+
+// MQTT5Output publishes readings using an MQTT 5 client, adding message
+// expiry, content type, user properties and topic aliases to state messages.
+type MQTT5Output struct {
+	cm             *autopaho.ConnectionManager
+	stateTopic     string
+	payload        *payloadEncoder
+	combined       bool
+	commandTopic   string
+	responseTopic  string
+	expiry         *uint32
+	contentType    string
+	userProperties paho.UserProperties
+	aliases        *topicAliases
+
+	mu      sync.Mutex
+	handler control.Handler
+}
+
+func newMQTT5(cfg Config, channels []config.ChannelConfig, enc *payloadEncoder, version string) (*MQTT5Output, error) {
+	m := &MQTT5Output{
+		stateTopic:    stateTopic(cfg),
+		payload:       enc,
+		combined:      cfg.Combined,
+		commandTopic:  cfg.CommandTopic,
+		responseTopic: cfg.ResponseTopic,
+		contentType:   cfg.ContentType,
+		aliases:       &topicAliases{limit: uint16(cfg.TopicAliasMaximum)},
+	}
+	if m.responseTopic == "" && m.commandTopic != "" {
+		m.responseTopic = m.commandTopic + responseTopicSuffix
+	}
+	if cfg.MessageExpiry > 0 {
+		e := uint32(cfg.MessageExpiry)
+		m.expiry = &e
+	}
+	if m.contentType == "" {
+		m.contentType = defaultContentType(enc)
+	}
+	if enc.device != "" {
+		m.userProperties.Add(userPropertyDevice, enc.device)
+	}
+	for k, v := range cfg.UserProperties {
+		m.userProperties.Add(k, v)
+	}
+
+	cm, err := connect5(cfg, m)
+	if err != nil {
+		return nil, err
+	}
+	m.cm = cm
+
+	if err := publishDiscovery(v5Publisher{cm: cm}, cfg, channels, enc, m.stateTopic, version); err != nil {
+		disconnect5(cm)
+		return nil, err
+	}
+	return m, nil
+}
+
+// connect5 creates an MQTT 5 connection manager (which reconnects
+// automatically) and waits for the first connection. m (optional) receives
+// connection and message callbacks.
+func connect5(cfg Config, m *MQTT5Output) (*autopaho.ConnectionManager, error) {
+	u, err := url.Parse(cfg.Server)
+	if err != nil {
+		return nil, fmt.Errorf("mqtt server: %w", err)
+	}
+	cc := autopaho.ClientConfig{
+		ServerUrls:                    []*url.URL{u},
+		KeepAlive:                     30,
+		CleanStartOnInitialConnection: true,
+		ConnectUsername:               cfg.Username,
+		ConnectPassword:               []byte(cfg.Password),
+		OnConnectError:                func(err error) { log.Printf("mqtt5: connect: %v", err) },
+		ClientConfig:                  paho.ClientConfig{ClientID: cfg.ClientID},
+	}
+	if m != nil {
+		cc.OnConnectionUp = m.onConnectionUp
+		cc.OnConnectionDown = m.onConnectionDown
+		cc.OnPublishReceived = []func(paho.PublishReceived) (bool, error){m.onPublishReceived}
+	}
+	cm, err := autopaho.NewConnection(context.Background(), cc)
+	if err != nil {
+		return nil, fmt.Errorf("mqtt connect: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout5)
+	defer cancel()
+	if err := cm.AwaitConnection(ctx); err != nil {
+		disconnect5(cm)
+		return nil, fmt.Errorf("mqtt connect: %w", err)
+	}
+	return cm, nil
+}
+
+// disconnect5 closes the connection and stops reconnection attempts.
+func disconnect5(cm *autopaho.ConnectionManager) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = cm.Disconnect(ctx)
+}
+
+// defaultContentType returns the content type matching the payload format.
+func defaultContentType(enc *payloadEncoder) string {
+	switch enc.format {
+	case PayloadFormatJSON:
+		return contentTypeJSON
+	case PayloadFormatValue:
+		return contentTypeText
+	default:
+		return ""
+	}
+}
+
+func (m *MQTT5Output) Publish(readings []sensor.Reading) error {
+	if m.combined {
+		b, err := m.payload.EncodeSnapshot(readings)
+		if err != nil {
+			return err
+		}
+		return m.publishState(m.stateTopic, b, "")
+	}
+	for _, r := range readings {
+		topic := formatStateTopic(m.stateTopic, r.Channel)
+		b, err := m.payload.Encode(r)
+		if err != nil {
+			return err
+		}
+		if err := m.publishState(topic, b, m.payload.data(r).Unit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishState publishes a state message with the configured MQTT 5 properties.
+func (m *MQTT5Output) publishState(topic string, payload []byte, unit string) error {
+	props := &paho.PublishProperties{ContentType: m.contentType, MessageExpiry: m.expiry}
+	props.User = append(props.User, m.userProperties...)
+	if unit != "" {
+		props.User.Add(userPropertyUnit, unit)
+	}
+	return m.aliases.send(topic, func(sendTopic string, alias *uint16) error {
+		props.TopicAlias = alias
+		ctx, cancel := context.WithTimeout(context.Background(), timeout5)
+		defer cancel()
+		_, err := m.cm.Publish(ctx, &paho.Publish{Topic: sendTopic, Payload: payload, Properties: props})
+		return err
+	})
+}
+
+func (m *MQTT5Output) Close() error {
+	if m.cm != nil {
+		disconnect5(m.cm)
+	}
+	return nil
+}
+
+// SetCommandHandler subscribes to the command topic (if configured) and
+// dispatches received commands to h. Responses are sent to the MQTT 5
+// response topic and correlation data of the request when present.
+func (m *MQTT5Output) SetCommandHandler(h control.Handler) error {
+	if m.commandTopic == "" {
+		return nil
+	}
+	m.mu.Lock()
+	m.handler = h
+	m.mu.Unlock()
+	return m.subscribeCommands(m.cm)
+}
+
+// onConnectionUp resets topic aliases for the new connection and restores the
+// command subscription.
+func (m *MQTT5Output) onConnectionUp(cm *autopaho.ConnectionManager, ack *paho.Connack) {
+	var brokerMax uint16
+	if ack.Properties != nil && ack.Properties.TopicAliasMaximum != nil {
+		brokerMax = *ack.Properties.TopicAliasMaximum
+	}
+	m.aliases.reset(brokerMax)
+	m.mu.Lock()
+	h := m.handler
+	m.mu.Unlock()
+	if h == nil {
+		return
+	}
+	// must not block the connection manager
+	go func() {
+		if err := m.subscribeCommands(cm); err != nil {
+			log.Printf("mqtt5: resubscribe %s: %v", m.commandTopic, err)
+		}
+	}()
+}
+
+// onConnectionDown disables topic aliases until the next connection is up:
+// the ones of the lost connection are not valid on the next one.
+func (m *MQTT5Output) onConnectionDown() bool {
+	m.aliases.reset(0)
+	return true
+}
+
+func (m *MQTT5Output) subscribeCommands(cm *autopaho.ConnectionManager) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout5)
+	defer cancel()
+	_, err := cm.Subscribe(ctx, &paho.Subscribe{Subscriptions: []paho.SubscribeOptions{{Topic: m.commandTopic, QoS: 1}}})
+	if err != nil {
+		return fmt.Errorf("mqtt subscribe %s: %w", m.commandTopic, err)
+	}
+	return nil
+}
+
+// onPublishReceived handles command messages.
+func (m *MQTT5Output) onPublishReceived(pr paho.PublishReceived) (bool, error) {
+	p := pr.Packet
+	if p.Topic != m.commandTopic {
+		return false, nil
+	}
+	m.mu.Lock()
+	h := m.handler
+	m.mu.Unlock()
+	if h == nil {
+		return false, nil
+	}
+	var cmd control.Command
+	var resp control.Response
+	if err := json.Unmarshal(p.Payload, &cmd); err != nil {
+		resp = control.Errorf(cmd, "invalid command: %v", err)
+	} else {
+		resp = h.Handle(cmd)
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return true, err
+	}
+	out := &paho.Publish{Topic: m.responseTopic, QoS: 1, Payload: b, Properties: &paho.PublishProperties{ContentType: contentTypeJSON}}
+	if p.Properties != nil {
+		if p.Properties.ResponseTopic != "" {
+			out.Topic = p.Properties.ResponseTopic
+		}
+		out.Properties.CorrelationData = p.Properties.CorrelationData
+	}
+	// publish asynchronously: waiting for the ack inside the receive callback would deadlock
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout5)
+		defer cancel()
+		if _, err := m.cm.Publish(ctx, out); err != nil {
+			log.Printf("mqtt5: command response: %v", err)
+		}
+	}()
+	return true, nil
+}
+
+// v5Publisher adapts an MQTT 5 connection manager to publisher.
+type v5Publisher struct{ cm *autopaho.ConnectionManager }
+
+func (p v5Publisher) publish(topic string, payload []byte, retained bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout5)
+	defer cancel()
+	_, err := p.cm.Publish(ctx, &paho.Publish{Topic: topic, Payload: payload, Retain: retained})
+	return err
+}
+
+// topicAliases assigns MQTT 5 topic aliases to state topics. The first message
+// on a topic carries both topic and alias; later ones only the alias. Aliases
+// are only valid for one connection: they are disabled when the connection is
+// lost and reset when the next one is up. Messages are sent holding the lock,
+// so a reset never happens between resolving an alias and sending it.
+type topicAliases struct {
+	mu     sync.Mutex
+	limit  uint16 // configured maximum (0 disables aliases)
+	max    uint16 // effective maximum for the current connection
+	topics map[string]uint16
+}
+
+// reset clears the aliases for a new connection whose broker accepts up to brokerMax aliases.
+func (t *topicAliases) reset(brokerMax uint16) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.max = t.limit
+	if brokerMax < t.max {
+		t.max = brokerMax
+	}
+	t.topics = make(map[string]uint16)
+}
+
+// send calls publish with the topic and alias to send for topic. An alias
+// introduced by a failed publish is forgotten, since the broker may not have
+// received it.
+func (t *topicAliases) send(topic string, publish func(topic string, alias *uint16) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sendTopic, alias := t.resolve(topic)
+	err := publish(sendTopic, alias)
+	if err != nil && sendTopic != "" && alias != nil {
+		delete(t.topics, topic)
+	}
+	return err
+}
+
+// resolve returns the topic and alias to send for topic. Topics without an
+// alias (aliases disabled or exhausted) are sent unchanged. t.mu is held.
+func (t *topicAliases) resolve(topic string) (string, *uint16) {
+	if t.max == 0 || strings.ContainsAny(topic, "+#") {
+		return topic, nil
+	}
+	if a, ok := t.topics[topic]; ok {
+		return "", &a
+	}
+	if len(t.topics) >= int(t.max) {
+		return topic, nil
+	}
+	a := uint16(len(t.topics) + 1)
+	t.topics[topic] = a
+	return topic, &a
+}
@@ -0,0 +1,66 @@
+package mqtt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTopicAliases(t *testing.T) {
+	a := &topicAliases{limit: 2}
+	a.reset(10)
+
+	topic, alias := a.resolve("ads1115/channel/0")
+	if topic != "ads1115/channel/0" || alias == nil || *alias != 1 {
+		t.Fatalf("first publish: topic=%q alias=%v", topic, alias)
+	}
+	topic, alias = a.resolve("ads1115/channel/0")
+	if topic != "" || alias == nil || *alias != 1 {
+		t.Fatalf("aliased publish: topic=%q alias=%v", topic, alias)
+	}
+	if _, alias = a.resolve("ads1115/channel/1"); alias == nil || *alias != 2 {
+		t.Fatalf("second topic alias: %v", alias)
+	}
+	// limit reached: topic is sent without alias
+	if topic, alias = a.resolve("ads1115/channel/2"); topic != "ads1115/channel/2" || alias != nil {
+		t.Fatalf("exhausted aliases: topic=%q alias=%v", topic, alias)
+	}
+
+	// a new connection forgets aliases and honours the broker maximum
+	a.reset(0)
+	if topic, alias = a.resolve("ads1115/channel/0"); topic != "ads1115/channel/0" || alias != nil {
+		t.Fatalf("broker without aliases: topic=%q alias=%v", topic, alias)
+	}
+}
+
+func TestTopicAliasesSend(t *testing.T) {
+	a := &topicAliases{limit: 4}
+	a.reset(4)
+	type sent struct {
+		topic string
+		alias uint16
+	}
+	var got []sent
+	publish := func(err error) func(string, *uint16) error {
+		return func(topic string, alias *uint16) error {
+			got = append(got, sent{topic, *alias})
+			return err
+		}
+	}
+	// the broker may not have received the alias of a failed publish
+	_ = a.send("ads1115/channel/0", publish(errors.New("timeout")))
+	_ = a.send("ads1115/channel/0", publish(nil))
+	_ = a.send("ads1115/channel/0", publish(nil))
+	want := []sent{{"ads1115/channel/0", 1}, {"ads1115/channel/0", 1}, {"", 1}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("sent %v, want %v", got, want)
+	}
+
+	// a lost connection disables aliases until the next one is up
+	m := &MQTT5Output{aliases: a}
+	if !m.onConnectionDown() {
+		t.Fatal("onConnectionDown stopped reconnecting")
+	}
+	if topic, alias := a.resolve("ads1115/channel/0"); topic != "ads1115/channel/0" || alias != nil {
+		t.Fatalf("aliases after connection loss: topic=%q alias=%v", topic, alias)
+	}
+}
@@ -0,0 +1,195 @@
+package mqtt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+var defaultPayloadFields = []string{"voltage", "raw"}
+
+// payloadData is the value passed to payload templates and used to build JSON payloads.
+type payloadData struct {
+	Channel   int
+	Name      string
+	Unit      string
+	Value     float64
+	Raw       int16
+	Timestamp time.Time
+	Device    string
+}
+
+// Voltage is an alias of Value so templates can use {{ .Voltage }}.
+func (d payloadData) Voltage() float64 { return d.Value }
+
+// payloadEncoder renders state payloads according to the configured payload format.
+type payloadEncoder struct {
+	format    string
+	fields    []string
+	precision int
+	tmpl      *template.Template
+	device    string
+	channels  output.Channels
+}
+
+func newPayloadEncoder(cfg Config, channels []config.ChannelConfig) (*payloadEncoder, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	e := &payloadEncoder{
+		format:    strings.ToLower(cfg.PayloadFormat),
+		fields:    cfg.PayloadFields,
+		precision: -1,
+		device:    cfg.ClientID,
+		channels:  output.NewChannels(channels),
+	}
+	if e.format == "" {
+		e.format = PayloadFormatJSON
+	}
+	if len(e.fields) == 0 {
+		e.fields = defaultPayloadFields
+	}
+	if cfg.PayloadPrecision != nil {
+		e.precision = *cfg.PayloadPrecision
+	}
+	if e.format == PayloadFormatTemplate {
+		t, err := template.New("payload").Parse(cfg.PayloadTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("payload_template: %w", err)
+		}
+		e.tmpl = t
+	}
+	if cfg.Combined {
+		if err := e.checkChannelKeys(); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// checkChannelKeys rejects channel keys that would overwrite each other in
+// combined payloads or break the quoting of their value templates.
+func (e *payloadEncoder) checkChannelKeys() error {
+	seen := make(map[string]int, len(e.channels))
+	for ch := range e.channels {
+		key := e.ChannelKey(ch)
+		if strings.ContainsAny(key, `'\`) {
+			return fmt.Errorf("combined: channel %d name %q contains a quote or backslash", ch, key)
+		}
+		if other, dup := seen[key]; dup {
+			return fmt.Errorf("combined: channels %d and %d have the same key %q", min(ch, other), max(ch, other), key)
+		}
+		seen[key] = ch
+	}
+	return nil
+}
+
+// data builds the template/JSON data for a reading.
+func (e *payloadEncoder) data(r sensor.Reading) payloadData {
+	ch := e.channels.Lookup(r.Channel)
+	return payloadData{Channel: r.Channel, Name: ch.Name, Unit: ch.UnitOrDefault(), Value: r.Value, Raw: r.Raw, Timestamp: r.Timestamp, Device: e.device}
+}
+
+// Encode renders the payload for a single reading.
+func (e *payloadEncoder) Encode(r sensor.Reading) ([]byte, error) {
+	d := e.data(r)
+	switch e.format {
+	case PayloadFormatValue:
+		return []byte(strconv.FormatFloat(d.Value, 'f', e.precision, 64)), nil
+	case PayloadFormatTemplate:
+		var buf bytes.Buffer
+		if err := e.tmpl.Execute(&buf, d); err != nil {
+			return nil, fmt.Errorf("payload template: %w", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return json.Marshal(e.fieldMap(d))
+	}
+}
+
+// EncodeSnapshot renders a single JSON document holding every reading of a
+// snapshot keyed by channel name (or index) with a shared timestamp and device.
+func (e *payloadEncoder) EncodeSnapshot(readings []sensor.Reading) ([]byte, error) {
+	var ts time.Time
+	chans := make(map[string]interface{}, len(readings))
+	for _, r := range readings {
+		d := e.data(r)
+		fields := e.fieldMap(d)
+		delete(fields, "timestamp")
+		delete(fields, "device")
+		chans[e.ChannelKey(r.Channel)] = fields
+		if r.Timestamp.After(ts) {
+			ts = r.Timestamp
+		}
+	}
+	payload := map[string]interface{}{
+		"timestamp": ts.Format(time.RFC3339Nano),
+		"channels":  chans,
+	}
+	if e.device != "" {
+		payload["device"] = e.device
+	}
+	return json.Marshal(payload)
+}
+
+// ChannelKey returns the key used for a channel in combined payloads: its name
+// when configured, otherwise its index.
+func (e *payloadEncoder) ChannelKey(channel int) string {
+	if ch, ok := e.channels[channel]; ok && ch.Name != "" {
+		return ch.Name
+	}
+	return strconv.Itoa(channel)
+}
+
+// SnapshotValueTemplate returns the Home Assistant value_template selecting a
+// channel's value from a combined payload.
+func (e *payloadEncoder) SnapshotValueTemplate(channel int) string {
+	return fmt.Sprintf("{{ value_json.channels['%s'].voltage }}", e.ChannelKey(channel))
+}
+
+// fieldMap returns the selected JSON fields for a reading.
+func (e *payloadEncoder) fieldMap(d payloadData) map[string]interface{} {
+	payload := make(map[string]interface{}, len(e.fields))
+	for _, f := range e.fields {
+		switch f {
+		case "voltage":
+			payload[f] = d.Value
+		case "raw":
+			payload[f] = d.Raw
+		case "channel":
+			payload[f] = d.Channel
+		case "name":
+			payload[f] = d.Name
+		case "unit":
+			payload[f] = d.Unit
+		case "timestamp":
+			payload[f] = d.Timestamp.Format(time.RFC3339Nano)
+		case "device":
+			payload[f] = d.Device
+		}
+	}
+	return payload
+}
+
+// ValueTemplate returns the Home Assistant value_template matching the payload format.
+func (e *payloadEncoder) ValueTemplate() string {
+	switch e.format {
+	case PayloadFormatJSON:
+		return valueTemplateVoltage
+	default:
+		return valueTemplatePlain
+	}
+}
+
+// IsJSON reports whether payloads are JSON objects usable as entity attributes.
+func (e *payloadEncoder) IsJSON() bool {
+	return e.format == PayloadFormatJSON
+}
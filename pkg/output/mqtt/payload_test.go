@@ -0,0 +1,90 @@
+package mqtt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+func TestPayloadEncoder(t *testing.T) {
+	prec := 2
+	channels := []config.ChannelConfig{{Channel: 1, Enabled: true, Name: "battery"}}
+	ts := time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)
+	r := sensor.Reading{Channel: 1, Raw: 1234, Value: 3.14159, Timestamp: ts}
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"default json", Config{}, `{"raw":1234,"voltage":3.14159}`},
+		{"json fields", Config{ClientID: "dev1", PayloadFields: []string{"voltage", "channel", "unit", "timestamp", "device"}}, `{"channel":1,"device":"dev1","timestamp":"2025-09-19T14:41:54Z","unit":"V","voltage":3.14159}`},
+		{"value", Config{PayloadFormat: "value", PayloadPrecision: &prec}, `3.14`},
+		{"value shortest", Config{PayloadFormat: "value"}, `3.14159`},
+		{"template", Config{PayloadFormat: "template", PayloadTemplate: `{{ .Name }}={{ printf "%.1f" .Voltage }}{{ .Unit }}@{{ .Timestamp.Unix }}`}, `battery=3.1V@1758292914`},
+	}
+	for _, tt := range tests {
+		enc, err := newPayloadEncoder(tt.cfg, channels)
+		if err != nil {
+			t.Fatalf("%s: newPayloadEncoder: %v", tt.name, err)
+		}
+		b, err := enc.Encode(r)
+		if err != nil {
+			t.Fatalf("%s: encode: %v", tt.name, err)
+		}
+		if string(b) != tt.want {
+			t.Fatalf("%s: got %s want %s", tt.name, b, tt.want)
+		}
+	}
+}
+
+func TestPayloadEncoderSnapshot(t *testing.T) {
+	channels := []config.ChannelConfig{{Channel: 0, Enabled: true, Name: "battery"}, {Channel: 1, Enabled: true}}
+	ts0 := time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)
+	ts1 := ts0.Add(time.Second)
+	readings := []sensor.Reading{
+		{Channel: 0, Raw: 100, Value: 1.5, Timestamp: ts0},
+		{Channel: 1, Raw: 200, Value: 2.5, Timestamp: ts1},
+	}
+	enc, err := newPayloadEncoder(Config{ClientID: "dev1", Combined: true, PayloadFields: []string{"voltage", "raw", "timestamp"}}, channels)
+	if err != nil {
+		t.Fatalf("newPayloadEncoder: %v", err)
+	}
+	b, err := enc.EncodeSnapshot(readings)
+	if err != nil {
+		t.Fatalf("encode snapshot: %v", err)
+	}
+	want := `{"channels":{"1":{"raw":200,"voltage":2.5},"battery":{"raw":100,"voltage":1.5}},"device":"dev1","timestamp":"2025-09-19T14:41:55Z"}`
+	if string(b) != want {
+		t.Fatalf("got %s want %s", b, want)
+	}
+	if got := enc.SnapshotValueTemplate(0); got != "{{ value_json.channels['battery'].voltage }}" {
+		t.Fatalf("value template ch0: %s", got)
+	}
+	if got := enc.SnapshotValueTemplate(1); got != "{{ value_json.channels['1'].voltage }}" {
+		t.Fatalf("value template ch1: %s", got)
+	}
+}
+
+func TestPayloadEncoderSnapshotKeys(t *testing.T) {
+	tests := []struct {
+		channels []config.ChannelConfig
+		want     string
+	}{
+		{[]config.ChannelConfig{{Channel: 0, Name: "battery"}, {Channel: 1, Name: "battery"}}, `channels 0 and 1 have the same key "battery"`},
+		{[]config.ChannelConfig{{Channel: 0}, {Channel: 1, Name: "0"}}, `channels 0 and 1 have the same key "0"`},
+		{[]config.ChannelConfig{{Channel: 2, Name: "driver's seat"}}, "contains a quote"},
+	}
+	for _, tt := range tests {
+		_, err := newPayloadEncoder(Config{Combined: true}, tt.channels)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("%+v: err = %v, want %q", tt.channels, err, tt.want)
+		}
+		// the names only matter to combined payloads
+		if _, err := newPayloadEncoder(Config{}, tt.channels); err != nil {
+			t.Fatalf("%+v: %v", tt.channels, err)
+		}
+	}
+}
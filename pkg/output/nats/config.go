@@ -0,0 +1,56 @@
+package nats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Config holds the settings of the NATS output.
+type Config struct {
+	// URL is the server URL, or a comma-separated list of URLs ("tls://" enables TLS).
+	URL string `json:"url"`
+	// SubjectTemplate builds the subject of a reading from {device}, {channel},
+	// {channel_name} (name or ch<N>) and {unit}. Default: "sensors.{device}.{channel}".
+	SubjectTemplate string `json:"subject_template,omitempty"`
+	// Device is the value of {device} and the "device" field. Default: "ads1115".
+	Device string `json:"device,omitempty"`
+	// CredentialsFile is a NATS .creds file (user JWT and NKey seed).
+	CredentialsFile string `json:"credentials_file,omitempty"`
+	Username        string `json:"username,omitempty"`
+	Password        string `json:"password,omitempty"`
+	Token           string `json:"token,omitempty"`
+	// TLSCAFile verifies the server; TLSCertFile and TLSKeyFile authenticate the client.
+	TLSCAFile             string `json:"tls_ca_file,omitempty"`
+	TLSCertFile           string `json:"tls_cert_file,omitempty"`
+	TLSKeyFile            string `json:"tls_key_file,omitempty"`
+	TLSInsecureSkipVerify bool   `json:"tls_insecure_skip_verify,omitempty"`
+	// JetStream publishes through JetStream, waiting for the acknowledgement of
+	// the stream and setting a deduplication id (device.channel.unix-nanoseconds).
+	JetStream bool `json:"jetstream,omitempty"`
+	// AckTimeoutMs bounds the wait for a JetStream acknowledgement. Default: 5000.
+	AckTimeoutMs int `json:"ack_timeout_ms,omitempty"`
+}
+
+// Validate checks the URL, subject template, credentials and TLS files.
+func (c Config) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	if c.SubjectTemplate != "" {
+		for _, tok := range strings.Split(c.SubjectTemplate, ".") {
+			if tok == "" || strings.ContainsAny(tok, "*> \t\r\n") {
+				return fmt.Errorf("invalid subject_template %q", c.SubjectTemplate)
+			}
+		}
+	}
+	if c.CredentialsFile != "" && (c.Username != "" || c.Token != "") {
+		return fmt.Errorf("use either credentials_file, username/password or token")
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("tls_cert_file and tls_key_file must be set together")
+	}
+	if c.AckTimeoutMs < 0 {
+		return fmt.Errorf("invalid ack_timeout_ms %d", c.AckTimeoutMs)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Package nats implements an output publishing each reading as JSON on a NATS
+// subject built from a template, optionally through JetStream with publish
+// acknowledgements and deduplication ids.
+package nats
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output/netsink"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	DefaultSubjectTemplate = "sensors.{device}.{channel}"
+	DefaultDevice          = "ads1115"
+	DefaultAckTimeoutMs    = 5000
+	clientName             = "ads1115-to-mqtt"
+)
+
+// reading is the JSON payload of a message.
+type reading struct {
+	Device string `json:"device"`
+	output.Reading
+}
+
+type NATSOutput struct {
+	nc         *natsgo.Conn
+	js         jetstream.JetStream
+	subject    netsink.MetricPath
+	device     string
+	ackTimeout time.Duration
+	channels   output.Channels
+}
+
+func init() {
+	output.Register("nats", output.Registration{
+		Options:  func() any { return &Config{} },
+		Required: true,
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewNATS(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+// NewNATS connects to the server; the connection reconnects indefinitely and
+// buffers core publishes while disconnected.
+func NewNATS(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.SubjectTemplate == "" {
+		cfg.SubjectTemplate = DefaultSubjectTemplate
+	}
+	if cfg.Device == "" {
+		cfg.Device = DefaultDevice
+	}
+	ackTimeout := cfg.AckTimeoutMs
+	if ackTimeout == 0 {
+		ackTimeout = DefaultAckTimeoutMs
+	}
+	nc, err := natsgo.Connect(cfg.URL, connectOptions(cfg)...)
+	if err != nil {
+		return nil, fmt.Errorf("nats connect: %w", err)
+	}
+	n := &NATSOutput{
+		nc:         nc,
+		subject:    netsink.NewMetricPath(cfg.SubjectTemplate, cfg.Device),
+		device:     cfg.Device,
+		ackTimeout: time.Duration(ackTimeout) * time.Millisecond,
+		channels:   output.NewChannels(channels),
+	}
+	if cfg.JetStream {
+		if n.js, err = jetstream.New(nc); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("nats jetstream: %w", err)
+		}
+	}
+	return n, nil
+}
+
+func connectOptions(cfg Config) []natsgo.Option {
+	opts := []natsgo.Option{
+		natsgo.Name(clientName),
+		natsgo.MaxReconnects(-1),
+		natsgo.DisconnectErrHandler(func(_ *natsgo.Conn, err error) {
+			if err != nil {
+				log.Printf("warning: nats disconnected: %v", err)
+			}
+		}),
+		natsgo.ReconnectHandler(func(nc *natsgo.Conn) {
+			log.Printf("nats reconnected to %s", nc.ConnectedUrlRedacted())
+		}),
+	}
+	switch {
+	case cfg.CredentialsFile != "":
+		opts = append(opts, natsgo.UserCredentials(cfg.CredentialsFile))
+	case cfg.Token != "":
+		opts = append(opts, natsgo.Token(cfg.Token))
+	case cfg.Username != "":
+		opts = append(opts, natsgo.UserInfo(cfg.Username, cfg.Password))
+	}
+	if cfg.TLSCAFile != "" {
+		opts = append(opts, natsgo.RootCAs(cfg.TLSCAFile))
+	}
+	if cfg.TLSCertFile != "" {
+		opts = append(opts, natsgo.ClientCert(cfg.TLSCertFile, cfg.TLSKeyFile))
+	}
+	if cfg.TLSInsecureSkipVerify {
+		opts = append(opts, natsgo.Secure(&tls.Config{InsecureSkipVerify: true}))
+	}
+	return opts
+}
+
+// Publish sends one message per reading. With JetStream every message waits
+// for the stream acknowledgement; a redelivered message (same device, channel
+// and timestamp) is acknowledged as a duplicate and not stored twice.
+func (n *NATSOutput) Publish(readings []sensor.Reading) error {
+	for _, r := range readings {
+		ch := n.channels.Lookup(r.Channel)
+		msg := natsgo.NewMsg(n.subject.Render(ch))
+		var err error
+		msg.Data, err = json.Marshal(reading{Device: n.device, Reading: n.channels.Reading(r)})
+		if err != nil {
+			return err
+		}
+		if n.js == nil {
+			if err := n.nc.PublishMsg(msg); err != nil {
+				return fmt.Errorf("nats publish %s: %w", msg.Subject, err)
+			}
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), n.ackTimeout)
+		_, err = n.js.PublishMsg(ctx, msg, jetstream.WithMsgID(n.msgID(r)))
+		cancel()
+		if err != nil {
+			return fmt.Errorf("nats jetstream publish %s: %w", msg.Subject, err)
+		}
+	}
+	return nil
+}
+
+// msgID returns the JetStream deduplication id of a reading.
+func (n *NATSOutput) msgID(r sensor.Reading) string {
+	return n.device + "." + strconv.Itoa(r.Channel) + "." + strconv.FormatInt(r.Timestamp.UnixNano(), 10)
+}
+
+// Close flushes pending messages and closes the connection.
+func (n *NATSOutput) Close() error {
+	err := n.nc.FlushTimeout(n.ackTimeout)
+	n.nc.Close()
+	if err != nil && err != natsgo.ErrConnectionClosed {
+		return fmt.Errorf("nats flush: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+var testReadings = []sensor.Reading{{Channel: 1, Raw: 19023, Value: 3.72, Timestamp: time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)}}
+
+// runServer starts an embedded server with JetStream.
+func runServer(t *testing.T) *server.Server {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoLog: true, NoSigs: true})
+	if err != nil {
+		t.Fatalf("server: %v", err)
+	}
+	go ns.Start()
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("server not ready")
+	}
+	t.Cleanup(ns.Shutdown)
+	return ns
+}
+
+func TestPublish(t *testing.T) {
+	ns := runServer(t)
+	nc, err := natsgo.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer nc.Close()
+	sub, err := nc.SubscribeSync("sensors.>")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	_ = nc.Flush()
+
+	o, err := NewNATS(Config{URL: ns.ClientURL(), Device: "bench", SubjectTemplate: "sensors.{device}.{channel_name}"}, []config.ChannelConfig{{Channel: 1, Name: "bat.1"}})
+	if err != nil {
+		t.Fatalf("NewNATS: %v", err)
+	}
+	if err := o.Publish(testReadings); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if err := o.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	msg, err := sub.NextMsg(2 * time.Second)
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if msg.Subject != "sensors.bench.bat_1" {
+		t.Fatalf("subject = %q", msg.Subject)
+	}
+	want := `{"device":"bench","channel":1,"name":"bat.1","unit":"V","value":3.72,"raw":19023,"timestamp":"2025-09-19T14:41:54Z"}`
+	if string(msg.Data) != want {
+		t.Fatalf("payload = %s, want %s", msg.Data, want)
+	}
+}
+
+func TestJetStreamDeduplication(t *testing.T) {
+	ns := runServer(t)
+	nc, err := natsgo.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer nc.Close()
+	js, _ := jetstream.New(nc)
+	ctx := context.Background()
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "SENSORS", Subjects: []string{"sensors.>"}})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	o, err := NewNATS(Config{URL: ns.ClientURL(), JetStream: true}, nil)
+	if err != nil {
+		t.Fatalf("NewNATS: %v", err)
+	}
+	defer o.Close()
+	// the same reading published twice is stored once
+	for i := 0; i < 2; i++ {
+		if err := o.Publish(testReadings); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatalf("info: %v", err)
+	}
+	if info.State.Msgs != 1 {
+		t.Fatalf("stream has %d messages, want 1", info.State.Msgs)
+	}
+	m, err := stream.GetLastMsgForSubject(ctx, "sensors.ads1115.1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	var r reading
+	if err := json.Unmarshal(m.Data, &r); err != nil || r.Raw != 19023 || m.Header.Get(natsgo.MsgIdHdr) != "ads1115.1.1758292914000000000" {
+		t.Fatalf("unexpected message %s %v (%v)", m.Data, m.Header, err)
+	}
+
+	// without a stream for the subject the publish fails instead of being lost
+	other, _ := NewNATS(Config{URL: ns.ClientURL(), JetStream: true, SubjectTemplate: "other.{channel}", AckTimeoutMs: 500}, nil)
+	defer other.Close()
+	if err := other.Publish(testReadings); err == nil {
+		t.Fatal("expected an error without a stream")
+	}
+}
@@ -0,0 +1,39 @@
+package netsink
+
+import (
+	"fmt"
+	"net"
+)
+
+// Config holds the settings of the statsd and graphite outputs.
+type Config struct {
+	// Address is the host:port of the StatsD daemon or Graphite (carbon) receiver.
+	Address string `json:"address"`
+	// Protocol is "udp" or "tcp". Default: "udp" for statsd, "tcp" for graphite.
+	Protocol string `json:"protocol,omitempty"`
+	// PathTemplate builds the metric path of a channel from {device}, {channel},
+	// {channel_name} (name or ch<N>) and {unit}. Default: "ads1115.{channel_name}".
+	PathTemplate string `json:"path_template,omitempty"`
+	// Device is the value of {device}. Default: "ads1115".
+	Device string `json:"device,omitempty"`
+	// Raw also sends the raw count as "<path>.raw".
+	Raw bool `json:"raw,omitempty"`
+	// MaxPacketSize is the maximum size of a packet carrying several metrics. Default: 1432.
+	MaxPacketSize int `json:"max_packet_size,omitempty"`
+}
+
+// Validate checks the address, protocol and packet size.
+func (c Config) Validate() error {
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("invalid address %q: %w", c.Address, err)
+	}
+	switch c.Protocol {
+	case "", "udp", "tcp":
+	default:
+		return fmt.Errorf("invalid protocol %q: use udp or tcp", c.Protocol)
+	}
+	if c.MaxPacketSize < 0 {
+		return fmt.Errorf("invalid max_packet_size %d", c.MaxPacketSize)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Package netsink holds the transport shared by the plaintext metric outputs
+// (statsd, graphite): metric path templates and a UDP/TCP sink batching lines
+// into packets and reconnecting TCP connections transparently.
+package netsink
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+)
+
+const (
+	DefaultPathTemplate  = "ads1115.{channel_name}"
+	DefaultDevice        = "ads1115"
+	DefaultMaxPacketSize = 1432
+	dialTimeout          = 5 * time.Second
+	writeTimeout         = 5 * time.Second
+)
+
+// unsafeChars matches characters replaced in path components so a value
+// never adds path levels or breaks the line format.
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// MetricPath renders metric paths from a template.
+type MetricPath struct {
+	template string
+	device   string
+}
+
+func NewMetricPath(template, device string) MetricPath {
+	if template == "" {
+		template = DefaultPathTemplate
+	}
+	if device == "" {
+		device = DefaultDevice
+	}
+	return MetricPath{template: template, device: device}
+}
+
+// Render returns the metric path of a channel.
+func (p MetricPath) Render(ch config.ChannelConfig) string {
+	name := ch.Name
+	if name == "" {
+		name = fmt.Sprintf("ch%d", ch.Channel)
+	}
+	return strings.NewReplacer(
+		"{device}", sanitize(p.device),
+		"{channel}", strconv.Itoa(ch.Channel),
+		"{channel_name}", sanitize(name),
+		"{unit}", sanitize(ch.UnitOrDefault()),
+	).Replace(p.template)
+}
+
+func sanitize(s string) string {
+	return unsafeChars.ReplaceAllString(s, "_")
+}
+
+// Sink sends newline-terminated lines over UDP or TCP.
+type Sink struct {
+	network   string
+	address   string
+	maxPacket int
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func NewSink(network, address string, maxPacket int) *Sink {
+	if maxPacket <= 0 {
+		maxPacket = DefaultMaxPacketSize
+	}
+	return &Sink{network: network, address: address, maxPacket: maxPacket}
+}
+
+// Send writes the lines in as few packets as possible, each at most the
+// maximum packet size (a longer line is sent alone). A failed TCP write is
+// retried once on a new connection. UDP is fire-and-forget: a write refused
+// because nothing listens at the address (reported by a previous ICMP port
+// unreachable) is not an error.
+func (s *Sink) Send(lines []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range Batch(lines, s.maxPacket) {
+		if err := s.write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *Sink) write(p []byte) error {
+	for attempt := 0; ; attempt++ {
+		if s.conn == nil {
+			conn, err := net.DialTimeout(s.network, s.address, dialTimeout)
+			if err != nil {
+				return fmt.Errorf("%s dial %s: %w", s.network, s.address, err)
+			}
+			s.conn = conn
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		_, err := s.conn.Write(p)
+		if err == nil || strings.HasPrefix(s.network, "udp") && errors.Is(err, syscall.ECONNREFUSED) {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+		if s.network != "tcp" || attempt > 0 {
+			return fmt.Errorf("%s write %s: %w", s.network, s.address, err)
+		}
+	}
+}
+
+// Batch groups lines into newline-terminated packets of at most max bytes.
+func Batch(lines []string, max int) [][]byte {
+	var packets [][]byte
+	var cur []byte
+	for _, l := range lines {
+		if len(cur) > 0 && len(cur)+len(l)+1 > max {
+			packets = append(packets, cur)
+			cur = nil
+		}
+		cur = append(cur, l...)
+		cur = append(cur, '\n')
+	}
+	if len(cur) > 0 {
+		packets = append(packets, cur)
+	}
+	return packets
+}
@@ -0,0 +1,97 @@
+package netsink
+
+import (
+	"bufio"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+)
+
+func TestMetricPath(t *testing.T) {
+	p := NewMetricPath("site.{device}.{channel_name}.{unit}.{channel}", "bench 1")
+	if got := p.Render(config.ChannelConfig{Channel: 2, Name: "tank.level", Unit: "%"}); got != "site.bench_1.tank_level._.2" {
+		t.Fatalf("got %q", got)
+	}
+	if got := NewMetricPath("", "").Render(config.ChannelConfig{Channel: 1}); got != "ads1115.ch1" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	got := Batch([]string{"aaaa", "bbbb", "cccc", "dddddddddddd"}, 10)
+	want := [][]byte{[]byte("aaaa\nbbbb\n"), []byte("cccc\n"), []byte("dddddddddddd\n")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestSinkTCPReconnects(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	received := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			sc := bufio.NewScanner(conn)
+			if sc.Scan() {
+				received <- sc.Text()
+			}
+			// drop the connection after the first line
+			conn.Close()
+		}
+	}()
+
+	s := NewSink("tcp", ln.Addr().String(), 0)
+	defer s.Close()
+	if err := s.Send([]string{"first"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got := <-received; got != "first" {
+		t.Fatalf("got %q", got)
+	}
+	// the server closed the connection: writes eventually fail and the sink redials
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if err := s.Send([]string{"second"}); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		select {
+		case got := <-received:
+			if got != "second" {
+				t.Fatalf("got %q", got)
+			}
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("line not received after reconnect")
+		}
+	}
+}
+
+func TestSinkUDPIgnoresRefused(t *testing.T) {
+	// a port nothing listens on: writes after the ICMP port unreachable are refused
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := pc.LocalAddr().String()
+	pc.Close()
+	s := NewSink("udp", addr, 0)
+	defer s.Close()
+	for i := 0; i < 5; i++ {
+		if err := s.Send([]string{"ads1115.ch0:1|g"}); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -1,10 +1,21 @@
 package output
 
-import "github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+import (
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
 
 type Output interface {
 	Publish([]sensor.Reading) error
 	Close() error
 }
 
+// ChannelUpdater is implemented by outputs announcing their channel set when
+// they start (Sparkplug DBIRTH, Homie nodes). UpdateChannels receives the
+// channels of the output after a remote command changed them and announces
+// the new set if needed.
+type ChannelUpdater interface {
+	UpdateChannels(channels []config.ChannelConfig) error
+}
+
 // helper constructors are in subpackages
@@ -0,0 +1,28 @@
+package prometheus
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Config holds the settings of the Prometheus exporter output.
+type Config struct {
+	// Address is the listen address of the metrics HTTP server. Default: ":9115".
+	Address string `json:"address,omitempty"`
+	// Path is the URL path serving the metrics. Default: "/metrics".
+	Path string `json:"path,omitempty"`
+}
+
+// Validate checks the listen address and metrics path.
+func (c Config) Validate() error {
+	if c.Address != "" {
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			return fmt.Errorf("invalid address %q: %w", c.Address, err)
+		}
+	}
+	if c.Path != "" && !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("invalid path %q: must start with '/'", c.Path)
+	}
+	return nil
+}
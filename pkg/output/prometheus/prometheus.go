@@ -0,0 +1,190 @@
+// Package prometheus implements an output serving the latest channel values
+// and the process read/publish counters in the Prometheus text exposition
+// format.
+package prometheus
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/stats"
+)
+
+const (
+	DefaultAddress = ":9115"
+	DefaultPath    = "/metrics"
+	// metric names
+	metricValue         = "ads1115_channel_value"
+	metricRaw           = "ads1115_channel_raw"
+	metricReads         = "ads1115_reads_total"
+	metricReadErrors    = "ads1115_read_errors_total"
+	metricPublishErrors = "ads1115_publish_errors_total"
+	metricLastRead      = "ads1115_last_read_timestamp_seconds"
+	metricOutPublished  = "ads1115_output_publishes_total"
+	metricOutErrors     = "ads1115_output_publish_errors_total"
+	metricOutDropped    = "ads1115_output_dropped_snapshots_total"
+	metricOutBuffered   = "ads1115_output_buffered_snapshots"
+	contentType         = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+type PrometheusOutput struct {
+	server   *http.Server
+	stats    *stats.Counters
+	channels output.Channels
+
+	mu     sync.Mutex
+	latest map[int]sensor.Reading
+}
+
+func init() {
+	output.Register("prometheus", output.Registration{
+		Options: func() any { return &Config{} },
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewPrometheus(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+// NewPrometheus starts the metrics HTTP server on the configured address.
+func NewPrometheus(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	addr := cfg.Address
+	if addr == "" {
+		addr = DefaultAddress
+	}
+	path := cfg.Path
+	if path == "" {
+		path = DefaultPath
+	}
+	p := newPrometheus(channels, stats.Default)
+	mux := http.NewServeMux()
+	mux.Handle(path, p)
+	p.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("prometheus listen: %w", err)
+	}
+	go func() {
+		if err := p.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("prometheus: serve: %v", err)
+		}
+	}()
+	return p, nil
+}
+
+func newPrometheus(channels []config.ChannelConfig, counters *stats.Counters) *PrometheusOutput {
+	return &PrometheusOutput{stats: counters, channels: output.NewChannels(channels), latest: make(map[int]sensor.Reading)}
+}
+
+// Publish stores the snapshot served on the next scrape.
+func (p *PrometheusOutput) Publish(readings []sensor.Reading) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, r := range readings {
+		p.latest[r.Channel] = r
+	}
+	return nil
+}
+
+// Close stops the metrics server.
+func (p *PrometheusOutput) Close() error {
+	if p.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return p.server.Shutdown(ctx)
+}
+
+func (p *PrometheusOutput) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	if err := p.write(w); err != nil {
+		log.Printf("prometheus: write metrics: %v", err)
+	}
+}
+
+// write renders all metrics in the text exposition format.
+func (p *PrometheusOutput) write(w io.Writer) error {
+	p.mu.Lock()
+	readings := make([]sensor.Reading, 0, len(p.latest))
+	for _, r := range p.latest {
+		readings = append(readings, r)
+	}
+	p.mu.Unlock()
+	sort.Slice(readings, func(i, j int) bool { return readings[i].Channel < readings[j].Channel })
+
+	bw := bufio.NewWriter(w)
+	header(bw, metricValue, "gauge", "Latest aggregated calibrated value of the channel.")
+	for _, r := range readings {
+		fmt.Fprintf(bw, "%s{%s} %s\n", metricValue, p.labels(r.Channel), formatFloat(r.Value))
+	}
+	header(bw, metricRaw, "gauge", "Latest aggregated raw ADC count of the channel.")
+	for _, r := range readings {
+		fmt.Fprintf(bw, "%s{%s} %d\n", metricRaw, p.labels(r.Channel), r.Raw)
+	}
+
+	s := p.stats.Snapshot()
+	header(bw, metricReads, "counter", "Successful sensor reads.")
+	fmt.Fprintf(bw, "%s %d\n", metricReads, s.Reads)
+	header(bw, metricReadErrors, "counter", "Failed sensor reads.")
+	fmt.Fprintf(bw, "%s %d\n", metricReadErrors, s.ReadErrors)
+	header(bw, metricPublishErrors, "counter", "Failed output publishes.")
+	fmt.Fprintf(bw, "%s %d\n", metricPublishErrors, s.PublishErrors)
+	if !s.LastRead.IsZero() {
+		header(bw, metricLastRead, "gauge", "Unix time of the last successful sensor read.")
+		fmt.Fprintf(bw, "%s %s\n", metricLastRead, formatFloat(float64(s.LastRead.UnixMilli())/1000))
+	}
+	if len(s.Outputs) > 0 {
+		for _, m := range []struct {
+			name, typ, help string
+			value           func(stats.OutputSnapshot) uint64
+		}{
+			{metricOutPublished, "counter", "Successful publishes of the output.", func(o stats.OutputSnapshot) uint64 { return o.Published }},
+			{metricOutErrors, "counter", "Failed publishes of the output, retries included.", func(o stats.OutputSnapshot) uint64 { return o.Errors }},
+			{metricOutDropped, "counter", "Snapshots the output gave up on.", func(o stats.OutputSnapshot) uint64 { return o.Dropped }},
+			{metricOutBuffered, "gauge", "Snapshots waiting to be published again by the output.", func(o stats.OutputSnapshot) uint64 { return uint64(o.Buffered) }},
+		} {
+			header(bw, m.name, m.typ, m.help)
+			for _, o := range s.Outputs {
+				fmt.Fprintf(bw, "%s{output=\"%d\",type=\"%s\"} %d\n", m.name, o.Index, escapeLabel(o.Type), m.value(o))
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// labels returns the channel, name and unit labels of a channel.
+func (p *PrometheusOutput) labels(channel int) string {
+	ch := p.channels.Lookup(channel)
+	return fmt.Sprintf(`channel="%d",name="%s",unit="%s"`, channel, escapeLabel(ch.Name), escapeLabel(ch.UnitOrDefault()))
+}
+
+func header(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// escapeLabel escapes a label value as required by the exposition format.
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
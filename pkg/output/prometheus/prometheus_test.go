@@ -0,0 +1,59 @@
+package prometheus
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/stats"
+)
+
+func TestMetrics(t *testing.T) {
+	counters := &stats.Counters{}
+	counters.RecordRead(time.Unix(1758292914, 500000000))
+	counters.RecordRead(time.Unix(1758292914, 500000000))
+	counters.RecordReadError()
+	out := counters.Output(1, "webhook", config.OnErrorBuffer)
+	out.RecordSuccess()
+	out.SetBuffered(2)
+	channels := []config.ChannelConfig{{Channel: 0, Enabled: true, Name: `bat "A"`}, {Channel: 1, Enabled: true, Unit: "A"}}
+	p := newPrometheus(channels, counters)
+	_ = p.Publish([]sensor.Reading{{Channel: 1, Raw: -12, Value: -0.0015}, {Channel: 0, Raw: 19023, Value: 3.72}})
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("content type = %q", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`ads1115_channel_value{channel="0",name="bat \"A\"",unit="V"} 3.72`,
+		`ads1115_channel_value{channel="1",name="",unit="A"} -0.0015`,
+		`ads1115_channel_raw{channel="0",name="bat \"A\"",unit="V"} 19023`,
+		"# TYPE ads1115_reads_total counter\nads1115_reads_total 2\n",
+		"ads1115_read_errors_total 1\n",
+		"ads1115_publish_errors_total 0\n",
+		"ads1115_last_read_timestamp_seconds 1.7582929145e+09\n",
+		`ads1115_output_publishes_total{output="1",type="webhook"} 1`,
+		"# TYPE ads1115_output_buffered_snapshots gauge\nads1115_output_buffered_snapshots{output=\"1\",type=\"webhook\"} 2\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+	if strings.Index(body, `channel="0"`) > strings.Index(body, `channel="1"`) {
+		t.Errorf("channels not sorted:\n%s", body)
+	}
+}
+
+func TestMetricsBeforeFirstRead(t *testing.T) {
+	p := newPrometheus(nil, &stats.Counters{})
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if strings.Contains(rec.Body.String(), "ads1115_last_read_timestamp_seconds ") {
+		t.Fatalf("last read timestamp exported before any read:\n%s", rec.Body.String())
+	}
+}
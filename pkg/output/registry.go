@@ -0,0 +1,131 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+)
+
+// Env is what a factory gets besides the options of its output.
+type Env struct {
+	// Config is the whole configuration, for outputs depending on other sections.
+	Config   *config.Config
+	Channels []config.ChannelConfig
+	// Version is the program version.
+	Version string
+}
+
+// Registration describes an output type. Output packages register their
+// types from init, so importing a package makes its outputs available.
+type Registration struct {
+	// Options returns a pointer to a new value, with the defaults set, that the
+	// options of an output are decoded into; a value with a Validate() error
+	// method is validated. Nil for types without options.
+	Options func() any
+	// Required rejects outputs of the type without options.
+	Required bool
+	// New builds an output from the value returned by Options (nil for types
+	// without options).
+	New func(opts any, env Env) (Output, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Registration)
+)
+
+// Register makes an output type available under a (case-insensitive) name.
+// It panics if the name is empty or already registered, or New is nil.
+func Register(typ string, r Registration) {
+	typ = strings.ToLower(typ)
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if typ == "" || r.New == nil {
+		panic("output: Register with an empty type or a nil New")
+	}
+	if _, dup := registry[typ]; dup {
+		panic("output: Register called twice for type " + typ)
+	}
+	registry[typ] = r
+}
+
+// Lookup returns the registration of an output type.
+func Lookup(typ string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[strings.ToLower(typ)]
+	return r, ok
+}
+
+// Types returns the registered output types, sorted.
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// DecodeOptions returns the options of an output: its options blob (or legacy
+// section, see config.OutputConfig) decoded over the defaults and validated,
+// or the defaults when it is not set.
+func DecodeOptions(o config.OutputConfig) (any, error) {
+	r, ok := Lookup(o.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown output type %q (known: %s)", o.Type, strings.Join(Types(), ", "))
+	}
+	typ := strings.ToLower(o.Type)
+	if r.Options == nil {
+		if len(o.Options) > 0 {
+			return nil, fmt.Errorf("%s output takes no options", typ)
+		}
+		return nil, nil
+	}
+	if len(o.Options) == 0 {
+		if r.Required {
+			return nil, fmt.Errorf("%s output requires options", typ)
+		}
+		return r.Options(), nil
+	}
+	opts := r.Options()
+	if err := json.Unmarshal(o.Options, opts); err != nil {
+		return nil, fmt.Errorf("%s options: %w", typ, err)
+	}
+	if v, ok := opts.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("%s options: %w", typ, err)
+		}
+	}
+	return opts, nil
+}
+
+// Validate checks that every output has a registered type and valid options.
+func Validate(outputs []config.OutputConfig) error {
+	for i, o := range outputs {
+		if _, err := DecodeOptions(o); err != nil {
+			return fmt.Errorf("outputs[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// New builds an output with the factory registered for its type.
+func New(o config.OutputConfig, env Env) (Output, error) {
+	opts, err := DecodeOptions(o)
+	if err != nil {
+		return nil, err
+	}
+	r, _ := Lookup(o.Type)
+	out, err := r.New(opts, env)
+	if err != nil {
+		return nil, fmt.Errorf("%s init: %w", strings.ToLower(o.Type), err)
+	}
+	return out, nil
+}
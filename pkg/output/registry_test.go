@@ -0,0 +1,83 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+type testOptions struct {
+	Target  string `json:"target"`
+	Retries int    `json:"retries"`
+}
+
+func (o testOptions) Validate() error {
+	if o.Target == "" {
+		return errors.New("target is required")
+	}
+	return nil
+}
+
+type testOutput struct{ opts testOptions }
+
+func (*testOutput) Publish([]sensor.Reading) error { return nil }
+func (*testOutput) Close() error                   { return nil }
+
+func init() {
+	Register("Test-Registry", Registration{
+		Options: func() any { return &testOptions{Retries: 3} },
+		New: func(opts any, _ Env) (Output, error) {
+			return &testOutput{opts: *opts.(*testOptions)}, nil
+		},
+	})
+	Register("test-bare", Registration{
+		New: func(any, Env) (Output, error) { return &testOutput{}, nil },
+	})
+	Register("test-required", Registration{
+		Options:  func() any { return &testOptions{} },
+		Required: true,
+		New:      func(any, Env) (Output, error) { return &testOutput{}, nil },
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	out, err := New(config.OutputConfig{Type: "test-registry", Options: json.RawMessage(`{"target":"x"}`)}, Env{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := out.(*testOutput).opts; got != (testOptions{Target: "x", Retries: 3}) {
+		t.Fatalf("options = %+v, defaults not kept", got)
+	}
+	// the legacy form of the options, a section named after the type
+	var legacy config.OutputConfig
+	if err := json.Unmarshal([]byte(`{"type":"test-required","test-required":{"target":"y"}}`), &legacy); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if opts, err := DecodeOptions(legacy); err != nil || opts.(*testOptions).Target != "y" {
+		t.Fatalf("legacy section = %v, %v", opts, err)
+	}
+
+	tests := []struct {
+		o    config.OutputConfig
+		want string
+	}{
+		{config.OutputConfig{Type: "nope"}, `unknown output type "nope"`},
+		{config.OutputConfig{Type: "test-registry", Options: json.RawMessage(`{}`)}, "target is required"},
+		{config.OutputConfig{Type: "test-registry", Options: json.RawMessage(`{"retries":"a"}`)}, "test-registry options"},
+		{config.OutputConfig{Type: "test-bare", Options: json.RawMessage(`{}`)}, "takes no options"},
+		{config.OutputConfig{Type: "test-required"}, "test-required output requires options"},
+	}
+	for _, tt := range tests {
+		err := Validate([]config.OutputConfig{{Type: "test-bare"}, tt.o})
+		if err == nil || !strings.HasPrefix(err.Error(), "outputs[1]: ") || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("%+v: err = %v, want %q", tt.o, err, tt.want)
+		}
+	}
+	if types := strings.Join(Types(), ","); types != "test-bare,test-registry,test-required" {
+		t.Fatalf("types = %s", types)
+	}
+}
@@ -0,0 +1,33 @@
+package sparkplug
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Config holds the settings of the Sparkplug B output.
+type Config struct {
+	Server   string `json:"server"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	// GroupID and EdgeNodeID identify the edge node in Sparkplug topics
+	// (spBv1.0/<group_id>/<type>/<edge_node_id>[/<device_id>]).
+	GroupID    string `json:"group_id"`
+	EdgeNodeID string `json:"edge_node_id"`
+	// DeviceID is the Sparkplug device carrying the channel metrics. Default: "ads1115".
+	DeviceID string `json:"device_id,omitempty"`
+}
+
+// Validate checks the Sparkplug identifiers.
+func (c Config) Validate() error {
+	if c.GroupID == "" || c.EdgeNodeID == "" {
+		return fmt.Errorf("group_id and edge_node_id are required")
+	}
+	for _, id := range []string{c.GroupID, c.EdgeNodeID, c.DeviceID} {
+		if strings.ContainsAny(id, "/+#") {
+			return fmt.Errorf("invalid sparkplug id %q: must not contain '/', '+' or '#'", id)
+		}
+	}
+	return nil
+}
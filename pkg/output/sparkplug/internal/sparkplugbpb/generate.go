@@ -0,0 +1,6 @@
+// Package sparkplugbpb holds the Sparkplug B payload messages generated from
+// the Eclipse Tahu schema (sparkplug_b.proto). The output encodes payloads
+// by hand; the tests decode them with these reference messages.
+package sparkplugbpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative sparkplug_b.proto
@@ -0,0 +1,231 @@
+package sparkplug
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Sparkplug B metric data types (org.eclipse.tahu.protobuf.DataType).
+const (
+	typeInt16   uint32 = 2
+	typeInt32   uint32 = 3
+	typeInt64   uint32 = 4
+	typeUInt64  uint32 = 8
+	typeDouble  uint32 = 10
+	typeBoolean uint32 = 11
+	typeString  uint32 = 12
+)
+
+// Field numbers of the Sparkplug B protobuf messages.
+const (
+	payloadTimestamp protowire.Number = 1
+	payloadMetrics   protowire.Number = 2
+	payloadSeq       protowire.Number = 3
+
+	metricName         protowire.Number = 1
+	metricAlias        protowire.Number = 2
+	metricTimestamp    protowire.Number = 3
+	metricDatatype     protowire.Number = 4
+	metricIsNull       protowire.Number = 7
+	metricProperties   protowire.Number = 9
+	metricIntValue     protowire.Number = 10
+	metricLongValue    protowire.Number = 11
+	metricDoubleValue  protowire.Number = 13
+	metricBooleanValue protowire.Number = 14
+	metricStringValue  protowire.Number = 15
+
+	propertySetKeys   protowire.Number = 1
+	propertySetValues protowire.Number = 2
+
+	propertyType        protowire.Number = 1
+	propertyIntValue    protowire.Number = 3
+	propertyDoubleValue protowire.Number = 6
+	propertyStringValue protowire.Number = 8
+)
+
+// payload is a Sparkplug B payload. Seq is omitted for NDEATH messages.
+type payload struct {
+	Timestamp uint64
+	Metrics   []metric
+	Seq       *uint64
+}
+
+// metric is a Sparkplug B metric. Value must match Datatype: int16/int32 for
+// Int16/Int32, int64/uint64 for Int64/UInt64, float64, bool or string. A nil
+// Value is sent with is_null set.
+type metric struct {
+	Name       string
+	Alias      uint64
+	Timestamp  uint64
+	Datatype   uint32
+	Value      interface{}
+	Properties []property
+}
+
+// property is an entry of a metric PropertySet (for example engUnit).
+type property struct {
+	Key   string
+	Type  uint32
+	Value interface{}
+}
+
+// marshal encodes the payload in protobuf wire format.
+func (p payload) marshal() []byte {
+	var b []byte
+	b = protowire.AppendTag(b, payloadTimestamp, protowire.VarintType)
+	b = protowire.AppendVarint(b, p.Timestamp)
+	for _, m := range p.Metrics {
+		b = protowire.AppendTag(b, payloadMetrics, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.marshal())
+	}
+	if p.Seq != nil {
+		b = protowire.AppendTag(b, payloadSeq, protowire.VarintType)
+		b = protowire.AppendVarint(b, *p.Seq)
+	}
+	return b
+}
+
+func (m metric) marshal() []byte {
+	var b []byte
+	if m.Name != "" {
+		b = protowire.AppendTag(b, metricName, protowire.BytesType)
+		b = protowire.AppendString(b, m.Name)
+	}
+	if m.Alias != 0 {
+		b = protowire.AppendTag(b, metricAlias, protowire.VarintType)
+		b = protowire.AppendVarint(b, m.Alias)
+	}
+	b = protowire.AppendTag(b, metricTimestamp, protowire.VarintType)
+	b = protowire.AppendVarint(b, m.Timestamp)
+	b = protowire.AppendTag(b, metricDatatype, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(m.Datatype))
+	if m.Value == nil {
+		b = protowire.AppendTag(b, metricIsNull, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(true))
+	}
+	if len(m.Properties) > 0 {
+		b = protowire.AppendTag(b, metricProperties, protowire.BytesType)
+		b = protowire.AppendBytes(b, marshalProperties(m.Properties))
+	}
+	return appendValue(b, m.Value, metricIntValue, metricLongValue, metricDoubleValue, metricBooleanValue, metricStringValue)
+}
+
+func marshalProperties(props []property) []byte {
+	var b []byte
+	for _, p := range props {
+		b = protowire.AppendTag(b, propertySetKeys, protowire.BytesType)
+		b = protowire.AppendString(b, p.Key)
+	}
+	for _, p := range props {
+		var v []byte
+		v = protowire.AppendTag(v, propertyType, protowire.VarintType)
+		v = protowire.AppendVarint(v, uint64(p.Type))
+		v = appendValue(v, p.Value, propertyIntValue, 0, propertyDoubleValue, 0, propertyStringValue)
+		b = protowire.AppendTag(b, propertySetValues, protowire.BytesType)
+		b = protowire.AppendBytes(b, v)
+	}
+	return b
+}
+
+// appendValue encodes a metric or property value in the oneof field matching
+// its Go type. Signed 8-32 bit integers are sent as uint32 two's complement.
+func appendValue(b []byte, v interface{}, intField, longField, doubleField, boolField, stringField protowire.Number) []byte {
+	switch x := v.(type) {
+	case int16:
+		b = protowire.AppendTag(b, intField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(uint32(int32(x))))
+	case int32:
+		b = protowire.AppendTag(b, intField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(uint32(x)))
+	case int64:
+		b = protowire.AppendTag(b, longField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(x))
+	case uint64:
+		b = protowire.AppendTag(b, longField, protowire.VarintType)
+		b = protowire.AppendVarint(b, x)
+	case float64:
+		b = protowire.AppendTag(b, doubleField, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(x))
+	case bool:
+		b = protowire.AppendTag(b, boolField, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(x))
+	case string:
+		b = protowire.AppendTag(b, stringField, protowire.BytesType)
+		b = protowire.AppendString(b, x)
+	}
+	return b
+}
+
+// commandMetric is the subset of an incoming command metric used by the output.
+type commandMetric struct {
+	Name      string
+	Alias     uint64
+	Boolean   bool
+	IsBoolean bool
+}
+
+// unmarshalCommandMetrics decodes the metrics of an NCMD/DCMD payload.
+func unmarshalCommandMetrics(b []byte) ([]commandMetric, error) {
+	var out []commandMetric
+	err := walkFields(b, func(num protowire.Number, typ protowire.Type, v []byte, x uint64) error {
+		if num != payloadMetrics || typ != protowire.BytesType {
+			return nil
+		}
+		var m commandMetric
+		err := walkFields(v, func(num protowire.Number, typ protowire.Type, v []byte, x uint64) error {
+			switch {
+			case num == metricName && typ == protowire.BytesType:
+				m.Name = string(v)
+			case num == metricAlias && typ == protowire.VarintType:
+				m.Alias = x
+			case num == metricBooleanValue && typ == protowire.VarintType:
+				m.Boolean, m.IsBoolean = protowire.DecodeBool(x), true
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		out = append(out, m)
+		return nil
+	})
+	return out, err
+}
+
+// walkFields calls fn for every field of a protobuf message. Bytes fields are
+// passed in v, varint and fixed fields in x.
+func walkFields(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte, x uint64) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("sparkplug payload: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		var v []byte
+		var x uint64
+		switch typ {
+		case protowire.VarintType:
+			x, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var x32 uint32
+			x32, n = protowire.ConsumeFixed32(b)
+			x = uint64(x32)
+		case protowire.Fixed64Type:
+			x, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("sparkplug payload: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		if err := fn(num, typ, v, x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
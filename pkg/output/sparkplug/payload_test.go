@@ -0,0 +1,147 @@
+package sparkplug
+
+import (
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	pb "github.com/ericogr/ads1115-to-mqtt/pkg/output/sparkplug/internal/sparkplugbpb"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+	"google.golang.org/protobuf/proto"
+)
+
+// goldenTS is the timestamp (ms) used by the test payloads.
+const goldenTS uint64 = 1758292914000
+
+// decode parses a payload with the reference Sparkplug B messages.
+func decode(t *testing.T, b []byte) *pb.Payload {
+	t.Helper()
+	var p pb.Payload
+	if err := proto.Unmarshal(b, &p); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return &p
+}
+
+func refMetric(name string, alias uint64, datatype pb.DataType) *pb.Payload_Metric {
+	m := &pb.Payload_Metric{Timestamp: proto.Uint64(goldenTS), Datatype: proto.Uint32(uint32(datatype))}
+	if name != "" {
+		m.Name = proto.String(name)
+	}
+	if alias != 0 {
+		m.Alias = proto.Uint64(alias)
+	}
+	return m
+}
+
+func engUnit(unit string) *pb.Payload_PropertySet {
+	return &pb.Payload_PropertySet{Keys: []string{propertyEngUnit}, Values: []*pb.Payload_PropertyValue{
+		{Type: proto.Uint32(uint32(pb.DataType_String)), Value: &pb.Payload_PropertyValue_StringValue{StringValue: unit}},
+	}}
+}
+
+func TestPayloadsDecodeWithReferenceSchema(t *testing.T) {
+	ts := time.UnixMilli(int64(goldenTS)).UTC()
+	channels := []config.ChannelConfig{{Channel: 0, Enabled: true, Name: "battery"}, {Channel: 1, Enabled: true, Unit: "A"}}
+	last := map[int]sensor.Reading{0: {Channel: 0, Raw: 19023, Value: 3.72, Timestamp: ts}}
+	readings := []sensor.Reading{{Channel: 0, Raw: 19023, Value: 3.72, Timestamp: ts}, {Channel: 1, Raw: -12, Value: -0.0015, Timestamp: ts}}
+
+	bdSeq := refMetric(metricBdSeq, 0, pb.DataType_Int64)
+	bdSeq.Value = &pb.Payload_Metric_LongValue{LongValue: 3}
+	rebirth := refMetric(metricRebirth, 0, pb.DataType_Boolean)
+	rebirth.Value = &pb.Payload_Metric_BooleanValue{BooleanValue: false}
+
+	batteryValue := refMetric("battery/value", 1, pb.DataType_Double)
+	batteryValue.Properties = engUnit("V")
+	batteryValue.Value = &pb.Payload_Metric_DoubleValue{DoubleValue: 3.72}
+	batteryRaw := refMetric("battery/raw", 2, pb.DataType_Int16)
+	batteryRaw.Value = &pb.Payload_Metric_IntValue{IntValue: 19023}
+	ch1Value := refMetric("ch1/value", 3, pb.DataType_Double)
+	ch1Value.Properties = engUnit("A")
+	ch1Value.IsNull = proto.Bool(true)
+	ch1Raw := refMetric("ch1/raw", 4, pb.DataType_Int16)
+	ch1Raw.IsNull = proto.Bool(true)
+
+	data := func(alias uint64, datatype pb.DataType, v any) *pb.Payload_Metric {
+		m := refMetric("", alias, datatype)
+		switch x := v.(type) {
+		case float64:
+			m.Value = &pb.Payload_Metric_DoubleValue{DoubleValue: x}
+		case int16:
+			// Int16 is sent as uint32 two's complement
+			m.Value = &pb.Payload_Metric_IntValue{IntValue: uint32(int32(x))}
+		}
+		return m
+	}
+
+	tests := []struct {
+		name string
+		p    payload
+		want *pb.Payload
+	}{
+		{"nbirth", nbirthPayload(goldenTS, 0, 3), &pb.Payload{Timestamp: proto.Uint64(goldenTS), Seq: proto.Uint64(0), Metrics: []*pb.Payload_Metric{bdSeq, rebirth}}},
+		{"dbirth", dbirthPayload(goldenTS, 1, channels, last), &pb.Payload{Timestamp: proto.Uint64(goldenTS), Seq: proto.Uint64(1), Metrics: []*pb.Payload_Metric{batteryValue, batteryRaw, ch1Value, ch1Raw}}},
+		{"ddata", ddataPayload(goldenTS, 2, readings), &pb.Payload{Timestamp: proto.Uint64(goldenTS), Seq: proto.Uint64(2), Metrics: []*pb.Payload_Metric{
+			data(1, pb.DataType_Double, 3.72), data(2, pb.DataType_Int16, int16(19023)),
+			data(3, pb.DataType_Double, -0.0015), data(4, pb.DataType_Int16, int16(-12)),
+		}}},
+		// NDEATH has no seq
+		{"ndeath", ndeathPayload(goldenTS, 3), &pb.Payload{Timestamp: proto.Uint64(goldenTS), Metrics: []*pb.Payload_Metric{bdSeq}}},
+	}
+	for _, tt := range tests {
+		if got := decode(t, tt.p.marshal()); !proto.Equal(got, tt.want) {
+			t.Fatalf("%s:\n got: %v\nwant: %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSessionWill(t *testing.T) {
+	s := &SparkplugOutput{cfg: Config{Server: "tcp://broker:1883"}, group: "g", node: "n"}
+	opts := s.clientOptions(7)
+	if opts.WillTopic != "spBv1.0/g/NDEATH/n" || !opts.WillEnabled || opts.AutoReconnect {
+		t.Fatalf("will topic %q enabled %v, auto reconnect %v", opts.WillTopic, opts.WillEnabled, opts.AutoReconnect)
+	}
+	will := decode(t, opts.WillPayload)
+	if m := will.GetMetrics(); len(m) != 1 || m[0].GetName() != metricBdSeq || m[0].GetLongValue() != 7 {
+		t.Fatalf("will metrics = %v", m)
+	}
+}
+
+func TestInitialBdSeq(t *testing.T) {
+	start := time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)
+	if a, b := initialBdSeq(start), initialBdSeq(start.Add(5*time.Second)); a >= seqModulo || b != (a+5)%seqModulo {
+		t.Fatalf("bdSeq of starts 5s apart = %d, %d", a, b)
+	}
+}
+
+func TestUpdateChannels(t *testing.T) {
+	channels := []config.ChannelConfig{{Channel: 0, Enabled: true}, {Channel: 1}}
+	s := &SparkplugOutput{client: mqtt.NewClient(mqtt.NewClientOptions()), channels: enabledChannels(channels), last: make(map[int]sensor.Reading)}
+	// readings of channels without a DBIRTH metric are not sent
+	if err := s.Publish([]sensor.Reading{{Channel: 1, Value: 1}}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	channels[1].Enabled = true
+	// not connected: the births of the next session define the new metrics
+	if err := s.UpdateChannels(channels); err != nil {
+		t.Fatalf("UpdateChannels: %v", err)
+	}
+	if len(s.channels) != 2 || s.channels[1].Channel != 1 {
+		t.Fatalf("channels = %+v", s.channels)
+	}
+}
+
+func TestUnmarshalRebirthCommand(t *testing.T) {
+	ncmd := payload{Timestamp: goldenTS, Metrics: []metric{{Name: metricRebirth, Timestamp: goldenTS, Datatype: typeBoolean, Value: true}}}
+	metrics, err := unmarshalCommandMetrics(ncmd.marshal())
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(metrics) != 1 || metrics[0].Name != metricRebirth || !metrics[0].IsBoolean || !metrics[0].Boolean {
+		t.Fatalf("metrics: %+v", metrics)
+	}
+	if _, err := unmarshalCommandMetrics([]byte{0x12, 0x05, 0x0a}); err == nil {
+		t.Fatalf("expected error for truncated payload")
+	}
+}
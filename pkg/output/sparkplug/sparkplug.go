@@ -0,0 +1,350 @@
+// Package sparkplug implements an output speaking Sparkplug B over MQTT: the
+// ADS1115 is published as a Sparkplug device of an edge node, with birth,
+// data and death certificates and rebirth requests handled on NCMD.
+package sparkplug
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	// DefaultDeviceID is the Sparkplug device id used when none is configured.
+	DefaultDeviceID = "ads1115"
+	namespace       = "spBv1.0"
+	metricBdSeq     = "bdSeq"
+	metricRebirth   = "Node Control/Rebirth"
+	propertyEngUnit = "engUnit"
+	// seqModulo bounds the Sparkplug sequence and bdSeq numbers (0..255)
+	seqModulo = 256
+	// reconnect backoff after a lost connection
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+type SparkplugOutput struct {
+	cfg      Config
+	group    string
+	node     string
+	device   string
+	channels []config.ChannelConfig
+
+	mu     sync.Mutex
+	client mqtt.Client
+	// bdSeq numbers the MQTT sessions: it is incremented on every connect and
+	// carried by the NBIRTH and the NDEATH will of the session. It starts from
+	// the clock so that a restarted process does not reuse the bdSeq of the
+	// previous one, whose will may still be delivered.
+	bdSeq  uint64
+	seq    uint64
+	last   map[int]sensor.Reading
+	closed bool
+	done   chan struct{}
+}
+
+func init() {
+	output.Register("sparkplug", output.Registration{
+		Options:  func() any { return &Config{} },
+		Required: true,
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewSparkplug(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+// NewSparkplug connects to the broker with an NDEATH will message and
+// publishes the node and device birth certificates.
+func NewSparkplug(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	s := &SparkplugOutput{cfg: cfg, group: cfg.GroupID, node: cfg.EdgeNodeID, device: cfg.DeviceID, last: make(map[int]sensor.Reading), done: make(chan struct{})}
+	if s.device == "" {
+		s.device = DefaultDeviceID
+	}
+	s.bdSeq = initialBdSeq(time.Now())
+	s.channels = enabledChannels(channels)
+	if err := s.connect(); err != nil {
+		return nil, fmt.Errorf("sparkplug connect: %w", err)
+	}
+	return s, nil
+}
+
+// clientOptions returns the options of a session numbered bdSeq, with its
+// NDEATH will. Reconnects are handled by the output so that every session
+// gets a new bdSeq and will.
+func (s *SparkplugOutput) clientOptions(bdSeq uint64) *mqtt.ClientOptions {
+	clientID := s.cfg.ClientID
+	if clientID == "" {
+		clientID = fmt.Sprintf("%s-%s", s.group, s.node)
+	}
+	opts := mqtt.NewClientOptions().AddBroker(s.cfg.Server).SetClientID(clientID).SetCleanSession(true)
+	if s.cfg.Username != "" {
+		opts.SetUsername(s.cfg.Username)
+	}
+	if s.cfg.Password != "" {
+		opts.SetPassword(s.cfg.Password)
+	}
+	opts.SetBinaryWill(s.topic("NDEATH", false), ndeathPayload(nowMillis(), bdSeq).marshal(), 1, false)
+	opts.SetAutoReconnect(false)
+	opts.SetConnectionLostHandler(s.onConnectionLost)
+	// births are published on every connection
+	opts.SetOnConnectHandler(s.onConnect)
+	return opts
+}
+
+// connect opens a new session with the current bdSeq.
+func (s *SparkplugOutput) connect() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errors.New("output closed")
+	}
+	client := mqtt.NewClient(s.clientOptions(s.bdSeq))
+	s.client = client
+	s.mu.Unlock()
+	token := client.Connect()
+	if token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+	return nil
+}
+
+// onConnectionLost reconnects with a new session, incrementing bdSeq before
+// every attempt, until it succeeds or the output is closed.
+func (s *SparkplugOutput) onConnectionLost(_ mqtt.Client, err error) {
+	log.Printf("sparkplug: connection lost: %v", err)
+	go func() {
+		delay := minReconnectDelay
+		for {
+			select {
+			case <-time.After(delay):
+			case <-s.done:
+				return
+			}
+			s.mu.Lock()
+			s.bdSeq = (s.bdSeq + 1) % seqModulo
+			s.mu.Unlock()
+			err := s.connect()
+			if err == nil {
+				return
+			}
+			log.Printf("sparkplug: reconnect: %v", err)
+			delay = min(2*delay, maxReconnectDelay)
+		}
+	}()
+}
+
+func (s *SparkplugOutput) Publish(readings []sensor.Reading) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// only the channels of the last DBIRTH have metric aliases
+	born := make([]sensor.Reading, 0, len(readings))
+	for _, r := range readings {
+		s.last[r.Channel] = r
+		if slices.ContainsFunc(s.channels, func(ch config.ChannelConfig) bool { return ch.Channel == r.Channel }) {
+			born = append(born, r)
+		}
+	}
+	if len(born) == 0 {
+		return nil
+	}
+	p := ddataPayload(nowMillis(), s.nextSeq(), born)
+	return s.publish(s.topic("DDATA", true), p)
+}
+
+// UpdateChannels implements output.ChannelUpdater: when the metrics of the
+// device change, a DDEATH and a DBIRTH with the new metrics are published.
+func (s *SparkplugOutput) UpdateChannels(channels []config.ChannelConfig) error {
+	enabled := enabledChannels(channels)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slices.EqualFunc(enabled, s.channels, sameMetrics) {
+		return nil
+	}
+	s.channels = enabled
+	// without a session the births of the next connection carry the new metrics
+	if s.closed || s.client == nil || !s.client.IsConnectionOpen() {
+		return nil
+	}
+	if err := s.publish(s.topic("DDEATH", true), payload{Timestamp: nowMillis(), Seq: ptr(s.nextSeq())}); err != nil {
+		return err
+	}
+	return s.publish(s.topic("DBIRTH", true), dbirthPayload(nowMillis(), s.nextSeq(), s.channels, s.last))
+}
+
+// Close publishes the device and node death certificates and disconnects.
+func (s *SparkplugOutput) Close() error {
+	s.mu.Lock()
+	if s.client == nil || s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	client := s.client
+	_ = s.publish(s.topic("DDEATH", true), payload{Timestamp: nowMillis(), Seq: ptr(s.nextSeq())})
+	_ = s.publish(s.topic("NDEATH", false), ndeathPayload(nowMillis(), s.bdSeq))
+	s.mu.Unlock()
+	client.Disconnect(250)
+	return nil
+}
+
+// onConnect publishes the births and subscribes to node commands.
+func (s *SparkplugOutput) onConnect(client mqtt.Client) {
+	token := client.Subscribe(s.topic("NCMD", false), 1, s.handleCommand)
+	if token.Wait() && token.Error() != nil {
+		log.Printf("sparkplug: subscribe NCMD: %v", token.Error())
+	}
+	if err := s.publishBirths(); err != nil {
+		log.Printf("sparkplug: birth: %v", err)
+	}
+}
+
+// handleCommand answers rebirth requests received on NCMD.
+func (s *SparkplugOutput) handleCommand(_ mqtt.Client, msg mqtt.Message) {
+	metrics, err := unmarshalCommandMetrics(msg.Payload())
+	if err != nil {
+		log.Printf("sparkplug: NCMD: %v", err)
+		return
+	}
+	for _, m := range metrics {
+		if m.Name == metricRebirth && m.IsBoolean && m.Boolean {
+			// do not block the message handler while waiting for the births
+			go func() {
+				if err := s.publishBirths(); err != nil {
+					log.Printf("sparkplug: rebirth: %v", err)
+				}
+			}()
+			return
+		}
+	}
+}
+
+// publishBirths publishes NBIRTH and DBIRTH, restarting the sequence at 0.
+func (s *SparkplugOutput) publishBirths() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq = 0
+	ts := nowMillis()
+	if err := s.publish(s.topic("NBIRTH", false), nbirthPayload(ts, s.nextSeq(), s.bdSeq)); err != nil {
+		return err
+	}
+	return s.publish(s.topic("DBIRTH", true), dbirthPayload(ts, s.nextSeq(), s.channels, s.last))
+}
+
+// nextSeq returns the next sequence number; callers must hold s.mu.
+func (s *SparkplugOutput) nextSeq() uint64 {
+	seq := s.seq
+	s.seq = (s.seq + 1) % seqModulo
+	return seq
+}
+
+// publish sends a message on the current session; callers must hold s.mu.
+func (s *SparkplugOutput) publish(topic string, p payload) error {
+	token := s.client.Publish(topic, 0, false, p.marshal())
+	token.Wait()
+	return token.Error()
+}
+
+// topic builds a Sparkplug topic for the node or, if device is set, the device.
+func (s *SparkplugOutput) topic(msgType string, device bool) string {
+	parts := []string{namespace, s.group, msgType, s.node}
+	if device {
+		parts = append(parts, s.device)
+	}
+	return strings.Join(parts, "/")
+}
+
+// initialBdSeq returns the bdSeq of the first session of a process started at t.
+func initialBdSeq(t time.Time) uint64 {
+	return uint64(t.Unix()) % seqModulo
+}
+
+// enabledChannels returns the channels defining device metrics.
+func enabledChannels(channels []config.ChannelConfig) []config.ChannelConfig {
+	var out []config.ChannelConfig
+	for _, ch := range channels {
+		if ch.Enabled {
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+// sameMetrics reports whether a and b define the same DBIRTH metrics.
+func sameMetrics(a, b config.ChannelConfig) bool {
+	return a.Channel == b.Channel && a.Name == b.Name && a.UnitOrDefault() == b.UnitOrDefault()
+}
+
+// nbirthPayload builds the node birth certificate.
+func nbirthPayload(ts, seq, bdSeq uint64) payload {
+	return payload{Timestamp: ts, Seq: ptr(seq), Metrics: []metric{
+		{Name: metricBdSeq, Timestamp: ts, Datatype: typeInt64, Value: int64(bdSeq)},
+		{Name: metricRebirth, Timestamp: ts, Datatype: typeBoolean, Value: false},
+	}}
+}
+
+// ndeathPayload builds the node death certificate (sent as MQTT will).
+func ndeathPayload(ts, bdSeq uint64) payload {
+	return payload{Timestamp: ts, Metrics: []metric{
+		{Name: metricBdSeq, Timestamp: ts, Datatype: typeInt64, Value: int64(bdSeq)},
+	}}
+}
+
+// dbirthPayload builds the device birth certificate defining a value and a raw
+// metric per enabled channel, with the last known values (or null).
+func dbirthPayload(ts, seq uint64, channels []config.ChannelConfig, last map[int]sensor.Reading) payload {
+	p := payload{Timestamp: ts, Seq: ptr(seq)}
+	for _, ch := range channels {
+		value := metric{Name: channelMetricName(ch, "value"), Alias: valueAlias(ch.Channel), Timestamp: ts, Datatype: typeDouble,
+			Properties: []property{{Key: propertyEngUnit, Type: typeString, Value: ch.UnitOrDefault()}}}
+		raw := metric{Name: channelMetricName(ch, "raw"), Alias: rawAlias(ch.Channel), Timestamp: ts, Datatype: typeInt16}
+		if r, ok := last[ch.Channel]; ok {
+			value.Value, value.Timestamp = r.Value, millis(r.Timestamp)
+			raw.Value, raw.Timestamp = r.Raw, millis(r.Timestamp)
+		}
+		p.Metrics = append(p.Metrics, value, raw)
+	}
+	return p
+}
+
+// ddataPayload builds a device data message using the aliases defined in DBIRTH.
+func ddataPayload(ts, seq uint64, readings []sensor.Reading) payload {
+	p := payload{Timestamp: ts, Seq: ptr(seq)}
+	for _, r := range readings {
+		rts := millis(r.Timestamp)
+		p.Metrics = append(p.Metrics,
+			metric{Alias: valueAlias(r.Channel), Timestamp: rts, Datatype: typeDouble, Value: r.Value},
+			metric{Alias: rawAlias(r.Channel), Timestamp: rts, Datatype: typeInt16, Value: r.Raw},
+		)
+	}
+	return p
+}
+
+// channelMetricName returns "<channel name or chN>/<suffix>".
+func channelMetricName(ch config.ChannelConfig, suffix string) string {
+	base := ch.Name
+	if base == "" {
+		base = fmt.Sprintf("ch%d", ch.Channel)
+	}
+	return base + "/" + suffix
+}
+
+// valueAlias and rawAlias return stable metric aliases for a channel.
+func valueAlias(ch int) uint64 { return uint64(ch)*2 + 1 }
+func rawAlias(ch int) uint64   { return uint64(ch)*2 + 2 }
+
+func millis(t time.Time) uint64 { return uint64(t.UnixMilli()) }
+func nowMillis() uint64         { return millis(time.Now()) }
+func ptr(v uint64) *uint64      { return &v }
@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"fmt"
+)
+
+// Config holds the settings of the SQLite history output.
+type Config struct {
+	// Path is the database file, created if missing.
+	Path string `json:"path"`
+	// RetentionDays removes rows older than this. Default: 30.
+	RetentionDays int `json:"retention_days,omitempty"`
+	// DownsampleAfterHours replaces rows older than this by one row per
+	// channel and DownsampleSeconds bucket (average, min, max); 0 disables
+	// downsampling. Default: 24.
+	DownsampleAfterHours *int `json:"downsample_after_hours,omitempty"`
+	// DownsampleSeconds is the bucket of downsampled rows. Default: 60.
+	DownsampleSeconds int `json:"downsample_seconds,omitempty"`
+}
+
+// Validate checks the path, retention and downsampling settings.
+func (c Config) Validate() error {
+	if c.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if c.RetentionDays < 0 || c.DownsampleSeconds < 0 || (c.DownsampleAfterHours != nil && *c.DownsampleAfterHours < 0) {
+		return fmt.Errorf("retention_days, downsample_after_hours and downsample_seconds must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+// Package sqlite implements an output keeping a local history of the
+// snapshots in a SQLite database (pure-Go driver), downsampling old rows and
+// pruning rows beyond the retention, and the queries of the history command.
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	DefaultRetentionDays        = 30
+	DefaultDownsampleAfterHours = 24
+	DefaultDownsampleSeconds    = 60
+	// maintenanceInterval is how often old rows are downsampled and pruned.
+	maintenanceInterval = time.Hour
+)
+
+// schema creates the tables. Timestamps are unix milliseconds; resolution_ms
+// is 0 for stored readings and the bucket size for downsampled rows, whose
+// raw is the rounded average and samples the number of readings merged.
+const schema = `
+CREATE TABLE IF NOT EXISTS channels (
+	channel INTEGER PRIMARY KEY,
+	name    TEXT NOT NULL,
+	unit    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS readings (
+	ts            INTEGER NOT NULL,
+	channel       INTEGER NOT NULL,
+	value         REAL    NOT NULL,
+	raw           INTEGER NOT NULL,
+	min           REAL    NOT NULL,
+	max           REAL    NOT NULL,
+	samples       INTEGER NOT NULL DEFAULT 1,
+	resolution_ms INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS readings_channel_ts ON readings (channel, ts);
+`
+
+// Row is a stored reading or downsampled bucket.
+type Row struct {
+	Time    time.Time
+	Channel int
+	Name    string
+	Unit    string
+	Value   float64
+	Raw     int64
+	Min     float64
+	Max     float64
+	Samples int64
+}
+
+type SQLiteOutput struct {
+	db              *sql.DB
+	retention       time.Duration
+	downsampleAfter time.Duration // 0: disabled
+	bucket          time.Duration
+	now             func() time.Time
+
+	mu   sync.Mutex // serializes writes with the maintenance
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Open opens (creating if needed) a history database.
+func Open(path string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("sqlite: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)")
+	if err != nil {
+		return nil, fmt.Errorf("sqlite open: %w", err)
+	}
+	// a single connection: writes are serialized anyway and pragmas apply once
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite schema: %w", err)
+	}
+	return db, nil
+}
+
+func init() {
+	output.Register("sqlite", output.Registration{
+		Options:  func() any { return &Config{} },
+		Required: true,
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewSQLite(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+// NewSQLite opens the database, stores the channel metadata and starts the
+// periodic downsampling and pruning.
+func NewSQLite(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	o, err := newSQLite(cfg, channels, time.Now)
+	if err != nil {
+		return nil, err
+	}
+	o.wg.Add(1)
+	go o.maintenanceLoop()
+	return o, nil
+}
+
+func newSQLite(cfg Config, channels []config.ChannelConfig, now func() time.Time) (*SQLiteOutput, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	retention, after, bucket := cfg.RetentionDays, DefaultDownsampleAfterHours, cfg.DownsampleSeconds
+	if retention == 0 {
+		retention = DefaultRetentionDays
+	}
+	if cfg.DownsampleAfterHours != nil {
+		after = *cfg.DownsampleAfterHours
+	}
+	if bucket == 0 {
+		bucket = DefaultDownsampleSeconds
+	}
+	db, err := Open(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	o := &SQLiteOutput{
+		db:              db,
+		retention:       time.Duration(retention) * 24 * time.Hour,
+		downsampleAfter: time.Duration(after) * time.Hour,
+		bucket:          time.Duration(bucket) * time.Second,
+		now:             now,
+		done:            make(chan struct{}),
+	}
+	for _, ch := range channels {
+		_, err := db.Exec(`INSERT INTO channels (channel, name, unit) VALUES (?, ?, ?)
+			ON CONFLICT (channel) DO UPDATE SET name = excluded.name, unit = excluded.unit`, ch.Channel, ch.Name, ch.UnitOrDefault())
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("sqlite channels: %w", err)
+		}
+	}
+	return o, nil
+}
+
+// Publish stores the snapshot in one transaction.
+func (o *SQLiteOutput) Publish(readings []sensor.Reading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	tx, err := o.db.Begin()
+	if err != nil {
+		return fmt.Errorf("sqlite: %w", err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`INSERT INTO readings (ts, channel, value, raw, min, max) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("sqlite: %w", err)
+	}
+	defer stmt.Close()
+	for _, r := range readings {
+		if _, err := stmt.Exec(r.Timestamp.UnixMilli(), r.Channel, r.Value, r.Raw, r.Value, r.Value); err != nil {
+			return fmt.Errorf("sqlite insert: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlite commit: %w", err)
+	}
+	return nil
+}
+
+// Close stops the maintenance and closes the database.
+func (o *SQLiteOutput) Close() error {
+	close(o.done)
+	o.wg.Wait()
+	return o.db.Close()
+}
+
+func (o *SQLiteOutput) maintenanceLoop() {
+	defer o.wg.Done()
+	ticker := time.NewTicker(maintenanceInterval)
+	defer ticker.Stop()
+	for {
+		if err := o.maintain(); err != nil {
+			log.Printf("warning: sqlite maintenance: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-o.done:
+			return
+		}
+	}
+}
+
+// maintain downsamples the rows older than downsample_after_hours and deletes
+// the rows older than the retention.
+func (o *SQLiteOutput) maintain() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := o.now()
+	tx, err := o.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if o.downsampleAfter > 0 {
+		b := o.bucket.Milliseconds()
+		// align the cutoff so a bucket is never split
+		cutoff := now.Add(-o.downsampleAfter).UnixMilli() / b * b
+		_, err := tx.Exec(`INSERT INTO readings (ts, channel, value, raw, min, max, samples, resolution_ms)
+			SELECT ts / ?1 * ?1, channel, SUM(value * samples) / SUM(samples), CAST(ROUND(1.0 * SUM(raw * samples) / SUM(samples)) AS INTEGER),
+				MIN(min), MAX(max), SUM(samples), ?1
+			FROM readings WHERE ts < ?2 AND resolution_ms < ?1
+			GROUP BY channel, ts / ?1`, b, cutoff)
+		if err != nil {
+			return fmt.Errorf("downsample: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM readings WHERE ts < ? AND resolution_ms < ?`, cutoff, b); err != nil {
+			return fmt.Errorf("downsample: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM readings WHERE ts < ?`, now.Add(-o.retention).UnixMilli()); err != nil {
+		return fmt.Errorf("prune: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Query returns the rows of a channel with from <= time < to, oldest first.
+func Query(db *sql.DB, channel int, from, to time.Time) ([]Row, error) {
+	rows, err := db.Query(`SELECT r.ts, r.channel, COALESCE(c.name, ''), COALESCE(c.unit, 'V'), r.value, r.raw, r.min, r.max, r.samples
+		FROM readings r LEFT JOIN channels c ON c.channel = r.channel
+		WHERE r.channel = ? AND r.ts >= ? AND r.ts < ? ORDER BY r.ts`, channel, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite query: %w", err)
+	}
+	defer rows.Close()
+	var out []Row
+	for rows.Next() {
+		var r Row
+		var ts int64
+		if err := rows.Scan(&ts, &r.Channel, &r.Name, &r.Unit, &r.Value, &r.Raw, &r.Min, &r.Max, &r.Samples); err != nil {
+			return nil, fmt.Errorf("sqlite query: %w", err)
+		}
+		r.Time = time.UnixMilli(ts).UTC()
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// WriteCSV writes rows as CSV with a header.
+func WriteCSV(w io.Writer, rows []Row) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"timestamp", "channel", "name", "unit", "value", "raw", "min", "max", "samples"})
+	for _, r := range rows {
+		_ = cw.Write([]string{
+			r.Time.Format(time.RFC3339Nano),
+			strconv.Itoa(r.Channel),
+			r.Name,
+			r.Unit,
+			formatFloat(r.Value),
+			strconv.FormatInt(r.Raw, 10),
+			formatFloat(r.Min),
+			formatFloat(r.Max),
+			strconv.FormatInt(r.Samples, 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteTable writes rows as aligned text for terminals.
+func WriteTable(w io.Writer, rows []Row) error {
+	if _, err := fmt.Fprintf(w, "%-24s %12s %7s %12s %12s %7s\n", "TIMESTAMP", "VALUE", "RAW", "MIN", "MAX", "SAMPLES"); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		_, err := fmt.Fprintf(w, "%-24s %12s %7d %12s %12s %7d\n", r.Time.Format("2006-01-02T15:04:05.000Z"),
+			formatFloat(r.Value)+" "+r.Unit, r.Raw, formatFloat(r.Min), formatFloat(r.Max), r.Samples)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
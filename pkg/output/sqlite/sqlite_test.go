@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+func TestPublishQueryAndMaintain(t *testing.T) {
+	now := time.Date(2025, 9, 19, 12, 0, 0, 0, time.UTC)
+	hours := 1
+	cfg := Config{Path: filepath.Join(t.TempDir(), "history.db"), RetentionDays: 2, DownsampleAfterHours: &hours, DownsampleSeconds: 60}
+	o, err := newSQLite(cfg, []config.ChannelConfig{{Channel: 0, Name: "battery"}}, func() time.Time { return now })
+	if err != nil {
+		t.Fatalf("newSQLite: %v", err)
+	}
+	defer o.Close()
+
+	old := now.Add(-3 * time.Hour).Truncate(time.Minute)
+	for i, v := range []float64{1, 2, 6} {
+		ts := old.Add(time.Duration(i) * 10 * time.Second)
+		if err := o.Publish([]sensor.Reading{{Channel: 0, Raw: int16(v * 10), Value: v, Timestamp: ts}, {Channel: 1, Value: 9, Timestamp: ts}}); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	recent := now.Add(-time.Minute)
+	expired := now.Add(-72 * time.Hour)
+	for _, ts := range []time.Time{recent, expired} {
+		if err := o.Publish([]sensor.Reading{{Channel: 0, Raw: 70, Value: 7, Timestamp: ts}}); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	if err := o.maintain(); err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+
+	rows, err := Query(o.db, 0, now.Add(-100*time.Hour), now)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2: %+v", len(rows), rows)
+	}
+	b := rows[0]
+	if !b.Time.Equal(old) || b.Value != 3 || b.Raw != 30 || b.Min != 1 || b.Max != 6 || b.Samples != 3 || b.Name != "battery" || b.Unit != "V" {
+		t.Fatalf("downsampled row = %+v", b)
+	}
+	if r := rows[1]; !r.Time.Equal(recent) || r.Value != 7 || r.Samples != 1 {
+		t.Fatalf("recent row = %+v", r)
+	}
+	// a second pass leaves the buckets alone
+	if err := o.maintain(); err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+	if again, _ := Query(o.db, 0, now.Add(-100*time.Hour), now); len(again) != 2 || again[0] != b {
+		t.Fatalf("rows after second maintenance = %+v", again)
+	}
+
+	var csv bytes.Buffer
+	if err := WriteCSV(&csv, rows[:1]); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	want := "timestamp,channel,name,unit,value,raw,min,max,samples\n2025-09-19T09:00:00Z,0,battery,V,3,30,1,6,3\n"
+	if csv.String() != want {
+		t.Fatalf("csv = %q, want %q", csv.String(), want)
+	}
+	var table bytes.Buffer
+	if err := WriteTable(&table, rows); err != nil || !strings.Contains(table.String(), "7 V") {
+		t.Fatalf("table = %q, %v", table.String(), err)
+	}
+}
@@ -0,0 +1,78 @@
+// Package statsd implements an output sending channel values as StatsD gauges.
+package statsd
+
+import (
+	"strconv"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output/netsink"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+// DefaultProtocol is the StatsD transport used when none is configured.
+const DefaultProtocol = "udp"
+
+type StatsDOutput struct {
+	sink     *netsink.Sink
+	path     netsink.MetricPath
+	raw      bool
+	channels output.Channels
+}
+
+func init() {
+	output.Register("statsd", output.Registration{
+		Options:  func() any { return &netsink.Config{} },
+		Required: true,
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewStatsD(*opts.(*netsink.Config), env.Channels)
+		},
+	})
+}
+
+func NewStatsD(cfg netsink.Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	protocol := cfg.Protocol
+	if protocol == "" {
+		protocol = DefaultProtocol
+	}
+	s := &StatsDOutput{
+		sink:     netsink.NewSink(protocol, cfg.Address, cfg.MaxPacketSize),
+		path:     netsink.NewMetricPath(cfg.PathTemplate, cfg.Device),
+		raw:      cfg.Raw,
+		channels: output.NewChannels(channels),
+	}
+	return s, nil
+}
+
+func (s *StatsDOutput) Publish(readings []sensor.Reading) error {
+	return s.sink.Send(s.lines(readings))
+}
+
+func (s *StatsDOutput) Close() error { return s.sink.Close() }
+
+// lines returns a "<path>:<value>|g" gauge per reading (and raw count).
+func (s *StatsDOutput) lines(readings []sensor.Reading) []string {
+	var lines []string
+	for _, r := range readings {
+		ch := s.channels.Lookup(r.Channel)
+		path := s.path.Render(ch)
+		lines = append(lines, gauge(path, strconv.FormatFloat(r.Value, 'f', -1, 64)))
+		if s.raw {
+			lines = append(lines, gauge(path+".raw", strconv.Itoa(int(r.Raw))))
+		}
+	}
+	return lines
+}
+
+// gauge formats a StatsD gauge. A signed value is a delta in StatsD, so a
+// negative value is sent as a reset to 0 followed by the negative delta (kept
+// in the same packet).
+func gauge(path, value string) string {
+	if value[0] == '-' {
+		return path + ":0|g\n" + path + ":" + value + "|g"
+	}
+	return path + ":" + value + "|g"
+}
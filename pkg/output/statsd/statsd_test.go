@@ -0,0 +1,39 @@
+package statsd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output/netsink"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+func TestPublishUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer pc.Close()
+	cfg := netsink.Config{Address: pc.LocalAddr().String(), PathTemplate: "site.{device}.{channel_name}", Device: "bench", Raw: true}
+	o, err := NewStatsD(cfg, []config.ChannelConfig{{Channel: 0, Name: "battery"}})
+	if err != nil {
+		t.Fatalf("NewStatsD: %v", err)
+	}
+	defer o.Close()
+	if err := o.Publish([]sensor.Reading{{Channel: 0, Raw: 19023, Value: 3.72}, {Channel: 1, Raw: -12, Value: -0.0015}}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	buf := make([]byte, 2048)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := "site.bench.battery:3.72|g\nsite.bench.battery.raw:19023|g\n" +
+		"site.bench.ch1:0|g\nsite.bench.ch1:-0.0015|g\nsite.bench.ch1.raw:0|g\nsite.bench.ch1.raw:-12|g\n"
+	if got := string(buf[:n]); got != want {
+		t.Fatalf("packet mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package syslog
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Severities maps the severity names accepted by the syslog and
+// journald outputs to their RFC 5424 values.
+var Severities = map[string]int{
+	"emerg": 0, "alert": 1, "crit": 2, "err": 3, "warning": 4, "notice": 5, "info": 6, "debug": 7,
+}
+
+// Facilities maps facility names to their RFC 5424 values.
+var Facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// Config holds the settings of the RFC 5424 syslog output.
+type Config struct {
+	// Network is "udp", "tcp" or "unix" (datagram, falling back to stream).
+	// Default: "unix".
+	Network string `json:"network,omitempty"`
+	// Address is the host:port of the collector or the socket path.
+	// Default: "/dev/log" for unix, required otherwise.
+	Address string `json:"address,omitempty"`
+	// Facility and Severity are names such as "local0" and "info".
+	// Defaults: "local0" and "info".
+	Facility string `json:"facility,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	// Hostname and AppName fill the header. Defaults: os.Hostname() and "ads1115".
+	Hostname string `json:"hostname,omitempty"`
+	AppName  string `json:"app_name,omitempty"`
+	// SDID is the structured-data element id. Default: "reading@32473".
+	SDID string `json:"sd_id,omitempty"`
+}
+
+// Validate checks the network, address, facility, severity and SD-ID.
+func (c Config) Validate() error {
+	switch c.Network {
+	case "", "unix":
+	case "udp", "tcp":
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			return fmt.Errorf("invalid address %q: %w", c.Address, err)
+		}
+	default:
+		return fmt.Errorf("invalid network %q: use udp, tcp or unix", c.Network)
+	}
+	if _, ok := Facilities[c.Facility]; c.Facility != "" && !ok {
+		return fmt.Errorf("invalid facility %q", c.Facility)
+	}
+	if _, ok := Severities[c.Severity]; c.Severity != "" && !ok {
+		return fmt.Errorf("invalid severity %q", c.Severity)
+	}
+	// SD-NAME: 1-32 printable US-ASCII characters except '=', ' ', ']' and '"'
+	if c.SDID != "" && (len(c.SDID) > 32 || strings.ContainsAny(c.SDID, "= ]\"") || !isPrintASCII(c.SDID)) {
+		return fmt.Errorf("invalid sd_id %q", c.SDID)
+	}
+	return nil
+}
+
+func isPrintASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 33 || s[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,218 @@
+// Package syslog implements an output sending one RFC 5424 message per
+// reading, with the channel, raw count and value as structured data, over
+// UDP, TCP (octet-counting framing, RFC 6587) or a local unix socket.
+package syslog
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	DefaultNetwork  = "unix"
+	DefaultAddress  = "/dev/log"
+	DefaultFacility = "local0"
+	DefaultSeverity = "info"
+	DefaultAppName  = "ads1115"
+	// DefaultSDID uses the private enterprise number reserved for
+	// documentation (RFC 5612).
+	DefaultSDID = "reading@32473"
+
+	msgID        = "reading"
+	timeFormat   = "2006-01-02T15:04:05.000000Z07:00"
+	maxHostname  = 255
+	maxAppName   = 48
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 5 * time.Second
+)
+
+// sdEscaper escapes the characters not allowed verbatim in a PARAM-VALUE.
+var sdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+type SyslogOutput struct {
+	network  string
+	address  string
+	pri      int
+	hostname string
+	appName  string
+	procID   string
+	sdID     string
+	channels output.Channels
+
+	mu     sync.Mutex
+	conn   net.Conn
+	stream bool // the connection needs framing
+}
+
+func init() {
+	output.Register("syslog", output.Registration{
+		Options: func() any { return &Config{} },
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewSyslog(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+func NewSyslog(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	s := &SyslogOutput{
+		network:  orDefault(cfg.Network, DefaultNetwork),
+		address:  cfg.Address,
+		pri:      Facilities[orDefault(cfg.Facility, DefaultFacility)]*8 + Severities[orDefault(cfg.Severity, DefaultSeverity)],
+		hostname: cfg.Hostname,
+		appName:  orDefault(cfg.AppName, DefaultAppName),
+		procID:   strconv.Itoa(os.Getpid()),
+		sdID:     orDefault(cfg.SDID, DefaultSDID),
+		channels: output.NewChannels(channels),
+	}
+	if s.network == "unix" && s.address == "" {
+		s.address = DefaultAddress
+	}
+	if s.hostname == "" {
+		s.hostname, _ = os.Hostname()
+	}
+	s.hostname = headerField(s.hostname, maxHostname)
+	s.appName = headerField(s.appName, maxAppName)
+	return s, nil
+}
+
+// Publish sends one message per reading; a failed write is retried once on a
+// new connection.
+func (s *SyslogOutput) Publish(readings []sensor.Reading) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range readings {
+		if err := s.send(s.format(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SyslogOutput) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// format renders the RFC 5424 message of a reading.
+func (s *SyslogOutput) format(r sensor.Reading) string {
+	ch := s.channels.Lookup(r.Channel)
+	name := ch.Name
+	if name == "" {
+		name = fmt.Sprintf("ch%d", r.Channel)
+	}
+	value := strconv.FormatFloat(r.Value, 'f', -1, 64)
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s [%s", s.pri, r.Timestamp.UTC().Format(timeFormat), s.hostname, s.appName, s.procID, msgID, s.sdID)
+	param(&b, "channel", strconv.Itoa(r.Channel))
+	if ch.Name != "" {
+		param(&b, "name", ch.Name)
+	}
+	param(&b, "unit", ch.UnitOrDefault())
+	param(&b, "raw", strconv.Itoa(int(r.Raw)))
+	param(&b, "value", value)
+	fmt.Fprintf(&b, "] %s=%s %s", name, value, ch.UnitOrDefault())
+	return b.String()
+}
+
+func param(b *strings.Builder, name, value string) {
+	b.WriteString(" " + name + `="` + sdEscaper.Replace(value) + `"`)
+}
+
+// send writes one message, framed on stream connections. Like the StatsD and
+// Graphite sinks, UDP is fire-and-forget: a write refused after an ICMP port
+// unreachable from a collector that is not running is not an error.
+func (s *SyslogOutput) send(msg string) error {
+	for attempt := 0; ; attempt++ {
+		if s.conn == nil {
+			if err := s.dial(); err != nil {
+				return err
+			}
+		}
+		frame := msg
+		if s.stream {
+			if s.network == "tcp" {
+				frame = strconv.Itoa(len(msg)) + " " + msg
+			} else {
+				frame = msg + "\n"
+			}
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		_, err := s.conn.Write([]byte(frame))
+		if err == nil || s.network == "udp" && errors.Is(err, syscall.ECONNREFUSED) {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+		if attempt > 0 {
+			return fmt.Errorf("syslog write %s: %w", s.address, err)
+		}
+	}
+}
+
+// dial connects to the collector. Local sockets are usually datagram sockets,
+// a stream socket is tried when the datagram connection is refused.
+func (s *SyslogOutput) dial() error {
+	var err error
+	switch s.network {
+	case "unix":
+		if s.conn, err = net.DialTimeout("unixgram", s.address, dialTimeout); err == nil {
+			s.stream = false
+			return nil
+		}
+		s.conn, err = net.DialTimeout("unix", s.address, dialTimeout)
+		s.stream = true
+	default:
+		s.conn, err = net.DialTimeout(s.network, s.address, dialTimeout)
+		s.stream = s.network == "tcp"
+	}
+	if err != nil {
+		s.conn = nil
+		return fmt.Errorf("syslog dial %s %s: %w", s.network, s.address, err)
+	}
+	return nil
+}
+
+// headerField returns s limited to printable US-ASCII and max bytes, or the
+// NILVALUE "-" when empty.
+func headerField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,111 @@
+package syslog
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+var testReadings = []sensor.Reading{{Channel: 0, Raw: 19023, Value: 3.72, Timestamp: time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)}}
+
+func TestUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer pc.Close()
+	cfg := Config{Network: "udp", Address: pc.LocalAddr().String(), Facility: "local3", Severity: "notice", Hostname: "bench 1"}
+	o, err := NewSyslog(cfg, []config.ChannelConfig{{Channel: 0, Name: `bat"1]`}})
+	if err != nil {
+		t.Fatalf("NewSyslog: %v", err)
+	}
+	defer o.Close()
+	if err := o.Publish(testReadings); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	buf := make([]byte, 1024)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	got := string(buf[:n])
+	// local3 (19) * 8 + notice (5) = 157
+	wantPrefix := "<157>1 2025-09-19T14:41:54.000000Z bench_1 ads1115 "
+	wantSuffix := ` reading [reading@32473 channel="0" name="bat\"1\]" unit="V" raw="19023" value="3.72"] bat"1]=3.72 V`
+	if !strings.HasPrefix(got, wantPrefix) || !strings.HasSuffix(got, wantSuffix) {
+		t.Fatalf("message = %q", got)
+	}
+}
+
+func TestUDPIgnoresRefused(t *testing.T) {
+	// a port nothing listens on: writes after the ICMP port unreachable are refused
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := pc.LocalAddr().String()
+	pc.Close()
+	o, err := NewSyslog(Config{Network: "udp", Address: addr}, nil)
+	if err != nil {
+		t.Fatalf("NewSyslog: %v", err)
+	}
+	defer o.Close()
+	var conn net.Conn
+	for i := 0; i < 5; i++ {
+		if err := o.Publish(testReadings); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+		// the refused writes keep the connection instead of redialing
+		if c := o.(*SyslogOutput).conn; conn == nil {
+			conn = c
+		} else if c != conn {
+			t.Fatalf("publish %d: connection replaced", i)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		n, _ := r.ReadString(' ')
+		rest := make([]byte, 200)
+		m, _ := r.Read(rest)
+		got <- n + string(rest[:m])
+	}()
+	o, err := NewSyslog(Config{Network: "tcp", Address: ln.Addr().String(), Hostname: "h", SDID: "ads@1"}, nil)
+	if err != nil {
+		t.Fatalf("NewSyslog: %v", err)
+	}
+	defer o.Close()
+	if err := o.Publish(testReadings); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	select {
+	case s := <-got:
+		n, msg, _ := strings.Cut(s, " ")
+		if n != strconv.Itoa(len(msg)) || !strings.HasPrefix(msg, "<134>1 ") || !strings.Contains(msg, `[ads@1 channel="0" unit="V"`) {
+			t.Fatalf("frame = %q", s)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+	}
+}
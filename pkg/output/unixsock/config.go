@@ -0,0 +1,35 @@
+package unixsock
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Config holds the settings of the unix socket output.
+type Config struct {
+	// Path is the socket file; a stale socket left by a previous run is replaced.
+	Path string `json:"path"`
+	// Mode is the octal permission of the socket file. Default: "0660".
+	Mode string `json:"mode,omitempty"`
+	// Group optionally changes the group owning the socket file.
+	Group string `json:"group,omitempty"`
+	// Buffer is the number of snapshots queued per client before it is
+	// disconnected. Default: 64.
+	Buffer int `json:"buffer,omitempty"`
+}
+
+// Validate checks the path, mode and buffer.
+func (c Config) Validate() error {
+	if c.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if c.Mode != "" {
+		if m, err := strconv.ParseUint(c.Mode, 8, 32); err != nil || m > 0o777 {
+			return fmt.Errorf("invalid mode %q: use an octal permission such as 0660", c.Mode)
+		}
+	}
+	if c.Buffer < 0 {
+		return fmt.Errorf("invalid buffer %d", c.Buffer)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+// Package unixsock implements an output listening on a unix domain socket
+// and streaming every snapshot as a line of JSON to the connected clients.
+package unixsock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/stream"
+)
+
+const (
+	DefaultMode  = 0o660
+	writeTimeout = 5 * time.Second
+)
+
+// snapshot is a line of the stream.
+type snapshot struct {
+	Timestamp time.Time        `json:"timestamp"`
+	Readings  []output.Reading `json:"readings"`
+}
+
+type UnixSocketOutput struct {
+	path     string
+	ln       net.Listener
+	hub      *stream.Hub
+	channels output.Channels
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func init() {
+	output.Register("unix", output.Registration{
+		Options:  func() any { return &Config{} },
+		Required: true,
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewUnixSocket(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+// NewUnixSocket listens on the socket path, replacing a stale socket file.
+func NewUnixSocket(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	mode := os.FileMode(DefaultMode)
+	if cfg.Mode != "" {
+		m, _ := strconv.ParseUint(cfg.Mode, 8, 32)
+		mode = os.FileMode(m)
+	}
+	if err := removeStale(cfg.Path); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unix socket listen: %w", err)
+	}
+	if err := setPermissions(cfg.Path, mode, cfg.Group); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	u := &UnixSocketOutput{
+		path:     cfg.Path,
+		ln:       ln,
+		hub:      stream.NewHub(cfg.Buffer),
+		channels: output.NewChannels(channels),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	u.wg.Add(1)
+	go u.serve()
+	return u, nil
+}
+
+// removeStale removes a socket file no process is listening on.
+func removeStale(path string) error {
+	st, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unix socket: %w", err)
+	}
+	if st.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("unix socket: %s exists and is not a socket", path)
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("unix socket: %s is in use", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("unix socket: %w", err)
+	}
+	return nil
+}
+
+func setPermissions(path string, mode os.FileMode, group string) error {
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("unix socket: %w", err)
+	}
+	if group == "" {
+		return nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return fmt.Errorf("unix socket: %w", err)
+	}
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return fmt.Errorf("unix socket: invalid gid %q", g.Gid)
+	}
+	if err := os.Chown(path, -1, gid); err != nil {
+		return fmt.Errorf("unix socket: %w", err)
+	}
+	return nil
+}
+
+// Publish queues the snapshot for every client; clients whose buffer is full
+// are disconnected.
+func (u *UnixSocketOutput) Publish(readings []sensor.Reading) error {
+	u.hub.Publish(stream.KindSnapshot, readings)
+	return nil
+}
+
+// Close stops listening, disconnects the clients and removes the socket file.
+func (u *UnixSocketOutput) Close() error {
+	u.mu.Lock()
+	if u.closed {
+		u.mu.Unlock()
+		return nil
+	}
+	u.closed = true
+	err := u.ln.Close()
+	for c := range u.conns {
+		c.Close()
+	}
+	u.mu.Unlock()
+	u.wg.Wait()
+	if rerr := os.Remove(u.path); rerr != nil && !errors.Is(rerr, os.ErrNotExist) && err == nil {
+		err = rerr
+	}
+	return err
+}
+
+func (u *UnixSocketOutput) serve() {
+	defer u.wg.Done()
+	for {
+		conn, err := u.ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("warning: unix socket accept: %v", err)
+			}
+			return
+		}
+		u.mu.Lock()
+		if u.closed {
+			u.mu.Unlock()
+			conn.Close()
+			return
+		}
+		u.conns[conn] = struct{}{}
+		u.wg.Add(1)
+		u.mu.Unlock()
+		go u.serveConn(conn)
+	}
+}
+
+// serveConn writes the snapshots to a client until it disconnects or falls
+// behind.
+func (u *UnixSocketOutput) serveConn(conn net.Conn) {
+	defer u.wg.Done()
+	sub := u.hub.Subscribe(stream.KindSnapshot, nil)
+	defer func() {
+		sub.Close()
+		u.mu.Lock()
+		delete(u.conns, conn)
+		u.mu.Unlock()
+		conn.Close()
+	}()
+	// clients only read; a read returning means the client went away
+	gone := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, conn)
+		close(gone)
+	}()
+	enc := json.NewEncoder(conn)
+	for {
+		select {
+		case readings := <-sub.Readings():
+			_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := enc.Encode(u.snapshot(readings)); err != nil {
+				return
+			}
+		case <-sub.Dropped():
+			log.Printf("warning: unix socket: client too slow, disconnected")
+			return
+		case <-gone:
+			return
+		}
+	}
+}
+
+func (u *UnixSocketOutput) snapshot(readings []sensor.Reading) snapshot {
+	s := snapshot{Readings: make([]output.Reading, 0, len(readings))}
+	for _, r := range readings {
+		s.Readings = append(s.Readings, u.channels.Reading(r))
+		if r.Timestamp.After(s.Timestamp) {
+			s.Timestamp = r.Timestamp
+		}
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+package unixsock
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+func TestStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ads1115.sock")
+	// a stale socket from a previous run is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	o, err := NewUnixSocket(Config{Path: path, Mode: "0600"}, []config.ChannelConfig{{Channel: 0, Name: "battery"}})
+	if err != nil {
+		t.Fatalf("NewUnixSocket: %v", err)
+	}
+	st, err := os.Stat(path)
+	if err != nil || st.Mode().Perm() != 0o600 {
+		t.Fatalf("socket mode = %v (%v), want 0600", st.Mode().Perm(), err)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	u := o.(*UnixSocketOutput)
+	deadline := time.Now().Add(2 * time.Second)
+	for u.hub.Clients() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("client not subscribed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	ts := time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)
+	_ = o.Publish([]sensor.Reading{{Channel: 0, Raw: 19023, Value: 3.72, Timestamp: ts}})
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := `{"timestamp":"2025-09-19T14:41:54Z","readings":[{"channel":0,"name":"battery","unit":"V","value":3.72,"raw":19023,"timestamp":"2025-09-19T14:41:54Z"}]}` + "\n"
+	if line != want {
+		t.Fatalf("line = %q, want %q", line, want)
+	}
+
+	if _, err := NewUnixSocket(Config{Path: path}, nil); err == nil {
+		t.Fatal("expected an error for a socket in use")
+	}
+	if err := o.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("socket file not removed: %v", err)
+	}
+}
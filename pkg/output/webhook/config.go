@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"fmt"
+	"net/url"
+	"text/template"
+)
+
+// Config holds the settings of the HTTP webhook output.
+type Config struct {
+	// URL receives a POST request per snapshot.
+	URL string `json:"url"`
+	// Headers are added to every request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// BearerToken is sent as "Authorization: Bearer <token>".
+	BearerToken string `json:"bearer_token,omitempty"`
+	// Username and Password enable basic auth when no bearer token is set.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Device is included in the JSON body and available to templates.
+	Device string `json:"device,omitempty"`
+	// BodyTemplate is a Go text/template rendering the body instead of JSON.
+	BodyTemplate string `json:"body_template,omitempty"`
+	// ContentType of the body. Default: "application/json".
+	ContentType string `json:"content_type,omitempty"`
+	// TimeoutMs is the request timeout. Default: 5000.
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+	// Retries is the number of retries of a failed request, on top of the
+	// on_error policy of the output. Default: 0.
+	Retries int `json:"retries,omitempty"`
+	// RetryBackoffMs is the delay before the first retry, doubled on every attempt. Default: 500.
+	RetryBackoffMs int `json:"retry_backoff_ms,omitempty"`
+	// HMACSecret signs the body with HMAC-SHA256 in SignatureHeader.
+	HMACSecret string `json:"hmac_secret,omitempty"`
+	// SignatureHeader carries "sha256=<hex>". Default: "X-Signature-256".
+	SignatureHeader string `json:"signature_header,omitempty"`
+}
+
+// Validate checks the URL, template and retry settings.
+func (c Config) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q", c.URL)
+	}
+	if c.BodyTemplate != "" {
+		if _, err := template.New("body").Parse(c.BodyTemplate); err != nil {
+			return fmt.Errorf("body_template: %w", err)
+		}
+	}
+	if c.TimeoutMs < 0 || c.RetryBackoffMs < 0 || c.Retries < 0 {
+		return fmt.Errorf("timeout_ms, retries and retry_backoff_ms must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Package webhook implements an output POSTing each snapshot to an HTTP
+// endpoint as JSON or a templated body, optionally signed with HMAC-SHA256.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	DefaultContentType     = "application/json"
+	DefaultSignatureHeader = "X-Signature-256"
+	DefaultTimeoutMs       = 5000
+	DefaultRetryBackoffMs  = 500
+	signaturePrefix        = "sha256="
+)
+
+// body is the JSON body and the data passed to body templates.
+type body struct {
+	Timestamp time.Time        `json:"timestamp"`
+	Device    string           `json:"device,omitempty"`
+	Readings  []output.Reading `json:"readings"`
+}
+
+type WebhookOutput struct {
+	client   *http.Client
+	cfg      Config
+	tmpl     *template.Template
+	channels output.Channels
+	retries  int
+	backoff  time.Duration
+}
+
+func init() {
+	output.Register("webhook", output.Registration{
+		Options:  func() any { return &Config{} },
+		Required: true,
+		New: func(opts any, env output.Env) (output.Output, error) {
+			return NewWebhook(*opts.(*Config), env.Channels)
+		},
+	})
+}
+
+func NewWebhook(cfg Config, channels []config.ChannelConfig) (output.Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.ContentType == "" {
+		cfg.ContentType = DefaultContentType
+	}
+	if cfg.SignatureHeader == "" {
+		cfg.SignatureHeader = DefaultSignatureHeader
+	}
+	timeout := cfg.TimeoutMs
+	if timeout == 0 {
+		timeout = DefaultTimeoutMs
+	}
+	backoff := cfg.RetryBackoffMs
+	if backoff == 0 {
+		backoff = DefaultRetryBackoffMs
+	}
+	w := &WebhookOutput{
+		client:   &http.Client{Timeout: time.Duration(timeout) * time.Millisecond},
+		cfg:      cfg,
+		channels: output.NewChannels(channels),
+		retries:  cfg.Retries,
+		backoff:  time.Duration(backoff) * time.Millisecond,
+	}
+	if cfg.BodyTemplate != "" {
+		t, err := template.New("body").Parse(cfg.BodyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("body_template: %w", err)
+		}
+		w.tmpl = t
+	}
+	return w, nil
+}
+
+// Publish sends the snapshot, retrying network errors, 429 and 5xx responses
+// with exponential backoff.
+func (w *WebhookOutput) Publish(readings []sensor.Reading) error {
+	b, err := w.encode(readings)
+	if err != nil {
+		return err
+	}
+	delay := w.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(b)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.retries {
+			return fmt.Errorf("webhook: %w", err)
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func (w *WebhookOutput) Close() error { return nil }
+
+// encode renders the request body.
+func (w *WebhookOutput) encode(readings []sensor.Reading) ([]byte, error) {
+	data := body{Device: w.cfg.Device, Readings: make([]output.Reading, 0, len(readings))}
+	for _, r := range readings {
+		data.Readings = append(data.Readings, w.channels.Reading(r))
+		if r.Timestamp.After(data.Timestamp) {
+			data.Timestamp = r.Timestamp
+		}
+	}
+	if w.tmpl == nil {
+		return json.Marshal(data)
+	}
+	var buf bytes.Buffer
+	if err := w.tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("body template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// post sends one request and reports whether a failure may be retried.
+func (w *WebhookOutput) post(b []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.cfg.URL, bytes.NewReader(b))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", w.cfg.ContentType)
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	switch {
+	case w.cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+w.cfg.BearerToken)
+	case w.cfg.Username != "":
+		req.SetBasicAuth(w.cfg.Username, w.cfg.Password)
+	}
+	if w.cfg.HMACSecret != "" {
+		req.Header.Set(w.cfg.SignatureHeader, Sign([]byte(w.cfg.HMACSecret), b))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
+
+// Sign returns the signature header value of body: "sha256=" followed by the
+// hex HMAC-SHA256 of the body keyed with secret.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+var testReadings = []sensor.Reading{{Channel: 0, Raw: 19023, Value: 3.72, Timestamp: time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)}}
+
+func TestPublishJSONSigned(t *testing.T) {
+	var got *http.Request
+	var gotBody []byte
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	cfg := Config{URL: srv.URL, Device: "bench", BearerToken: "tok", Headers: map[string]string{"X-Site": "lab"}, HMACSecret: "s3cret", Retries: 1, RetryBackoffMs: 1}
+	o, err := NewWebhook(cfg, []config.ChannelConfig{{Channel: 0, Name: "battery"}})
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+	if err := o.Publish(testReadings); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("got %d calls, want 2", calls)
+	}
+	want := `{"timestamp":"2025-09-19T14:41:54Z","device":"bench","readings":[{"channel":0,"name":"battery","unit":"V","value":3.72,"raw":19023,"timestamp":"2025-09-19T14:41:54Z"}]}`
+	if string(gotBody) != want {
+		t.Fatalf("body = %s, want %s", gotBody, want)
+	}
+	if got.Header.Get("Authorization") != "Bearer tok" || got.Header.Get("X-Site") != "lab" || got.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected headers %v", got.Header)
+	}
+	sig := got.Header.Get(DefaultSignatureHeader)
+	if !hmac.Equal([]byte(sig), []byte(Sign([]byte("s3cret"), gotBody))) || !strings.HasPrefix(sig, "sha256=") {
+		t.Fatalf("bad signature %q", sig)
+	}
+}
+
+func TestPublishTemplate(t *testing.T) {
+	var gotBody []byte
+	var user, pass string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		user, pass, _ = r.BasicAuth()
+	}))
+	defer srv.Close()
+	cfg := Config{URL: srv.URL, Username: "u", Password: "p", ContentType: "text/plain",
+		BodyTemplate: `{{range .Readings}}{{.Channel}}={{printf "%.2f" .Value}};{{end}}`}
+	o, err := NewWebhook(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+	if err := o.Publish(testReadings); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if string(gotBody) != "0=3.72;" || user != "u" || pass != "p" {
+		t.Fatalf("body %q auth %q:%q", gotBody, user, pass)
+	}
+}
+
+func TestPublishClientErrorNotRetried(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "nope", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	o, err := NewWebhook(Config{URL: srv.URL, Retries: 5, RetryBackoffMs: 1}, nil)
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+	if err := o.Publish(testReadings); err == nil || calls != 1 {
+		t.Fatalf("err=%v calls=%d, want error after 1 call", err, calls)
+	}
+}
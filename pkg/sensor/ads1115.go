@@ -2,6 +2,7 @@ package sensor
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
@@ -33,6 +34,8 @@ type ADS1115Sensor struct {
 	channelScales      map[int]float64
 	channelOffsets     map[int]float64
 	pgaFS              float64
+	// mu serializes bus transactions and guards the channel settings.
+	mu sync.Mutex
 }
 
 func NewADS1115Sensor(cfg config.Config) (Sensor, error) {
@@ -55,7 +58,17 @@ func (s *ADS1115Sensor) Close() error {
 	return nil
 }
 
+// UpdateChannels replaces the per-channel settings used by subsequent reads.
+func (s *ADS1115Sensor) UpdateChannels(channels []config.ChannelConfig) {
+	chans, cscale, coff, csr := buildChannelSettings(config.Config{Channels: channels})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels, s.channelScales, s.channelOffsets, s.channelSampleRates = chans, cscale, coff, csr
+}
+
 func (s *ADS1115Sensor) Read() ([]Reading, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	out := make([]Reading, 0, len(s.channels))
 
 	now := time.Now()
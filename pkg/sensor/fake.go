@@ -42,4 +42,12 @@ func (f *FakeSensor) Read() ([]Reading, error) {
 	return out, nil
 }
 
+// UpdateChannels replaces the per-channel settings used by subsequent reads.
+func (f *FakeSensor) UpdateChannels(channels []config.ChannelConfig) {
+	chans, scales, offs, _ := buildChannelSettings(config.Config{Channels: channels})
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.channels, f.channelScales, f.channelOffsets = chans, scales, offs
+}
+
 func (f *FakeSensor) Close() error { return nil }
@@ -1,6 +1,10 @@
 package sensor
 
-import "time"
+import (
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+)
 
 type Reading struct {
 	Channel   int       `json:"channel"`
@@ -13,3 +17,9 @@ type Sensor interface {
 	Read() ([]Reading, error)
 	Close() error
 }
+
+// Configurable is implemented by sensors whose channel settings (enabled
+// channels, calibration and sample rates) can be changed while running.
+type Configurable interface {
+	UpdateChannels(channels []config.ChannelConfig)
+}
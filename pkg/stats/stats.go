@@ -0,0 +1,168 @@
+// Package stats keeps process-wide counters about sensor reads and output
+// publishing. main records the events; outputs and status endpoints read them.
+package stats
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Counters holds read and publish counters. The zero value is ready to use and
+// all methods are safe for concurrent use.
+type Counters struct {
+	reads         atomic.Uint64
+	readErrors    atomic.Uint64
+	publishErrors atomic.Uint64
+	lastRead      atomic.Int64 // unix nanoseconds of the last successful read
+
+	mu      sync.Mutex
+	outputs map[int]*OutputCounters
+}
+
+// OutputCounters holds the publish counters of one output. All methods are
+// safe for concurrent use.
+type OutputCounters struct {
+	index    int
+	typ      string
+	policy   string
+	success  atomic.Uint64
+	errors   atomic.Uint64
+	retries  atomic.Uint64
+	dropped  atomic.Uint64
+	buffered atomic.Int64
+	failing  atomic.Uint64 // consecutive failed publishes
+
+	mu          sync.Mutex
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// OutputSnapshot is a point-in-time copy of OutputCounters.
+type OutputSnapshot struct {
+	Index int    `json:"index"`
+	Type  string `json:"type"`
+	// OnError is the error policy of the output.
+	OnError string `json:"on_error"`
+	// Published counts successful publishes, Errors failed ones (retries
+	// included) and Dropped the snapshots given up on.
+	Published uint64 `json:"published"`
+	Errors    uint64 `json:"errors"`
+	Retries   uint64 `json:"retries"`
+	Dropped   uint64 `json:"dropped"`
+	// Buffered is the number of snapshots waiting to be published again.
+	Buffered int `json:"buffered"`
+	// ConsecutiveErrors is 0 once a publish succeeds.
+	ConsecutiveErrors uint64    `json:"consecutive_errors"`
+	LastError         string    `json:"last_error,omitempty"`
+	LastErrorAt       time.Time `json:"last_error_at,omitzero"`
+}
+
+// Snapshot is a point-in-time copy of Counters.
+type Snapshot struct {
+	Reads         uint64    `json:"reads"`
+	ReadErrors    uint64    `json:"read_errors"`
+	PublishErrors uint64    `json:"publish_errors"`
+	LastRead      time.Time `json:"last_read"`
+	// Outputs holds the counters of each output, by position in the configuration.
+	Outputs []OutputSnapshot `json:"outputs,omitempty"`
+}
+
+// Default is the process-wide counter set fed by main.
+var Default = &Counters{}
+
+// RecordRead counts a successful sensor read done at t.
+func (c *Counters) RecordRead(t time.Time) {
+	c.reads.Add(1)
+	c.lastRead.Store(t.UnixNano())
+}
+
+// RecordReadError counts a failed sensor read.
+func (c *Counters) RecordReadError() { c.readErrors.Add(1) }
+
+// RecordPublishError counts a failed output publish.
+func (c *Counters) RecordPublishError() { c.publishErrors.Add(1) }
+
+// Snapshot returns the current counter values. LastRead is zero until the
+// first successful read.
+func (c *Counters) Snapshot() Snapshot {
+	s := Snapshot{
+		Reads:         c.reads.Load(),
+		ReadErrors:    c.readErrors.Load(),
+		PublishErrors: c.publishErrors.Load(),
+	}
+	if ns := c.lastRead.Load(); ns != 0 {
+		s.LastRead = time.Unix(0, ns)
+	}
+	c.mu.Lock()
+	for _, o := range c.outputs {
+		s.Outputs = append(s.Outputs, o.Snapshot())
+	}
+	c.mu.Unlock()
+	sort.Slice(s.Outputs, func(i, j int) bool { return s.Outputs[i].Index < s.Outputs[j].Index })
+	return s
+}
+
+// Output returns the counters of the output at index, of type typ and with
+// the error policy policy, replacing counters registered for another output
+// at that index.
+func (c *Counters) Output(index int, typ, policy string) *OutputCounters {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if o := c.outputs[index]; o != nil && o.typ == typ && o.policy == policy {
+		return o
+	}
+	if c.outputs == nil {
+		c.outputs = make(map[int]*OutputCounters)
+	}
+	o := &OutputCounters{index: index, typ: typ, policy: policy}
+	c.outputs[index] = o
+	return o
+}
+
+// RecordSuccess counts a successful publish and returns the number of
+// consecutive failed publishes it ends.
+func (o *OutputCounters) RecordSuccess() uint64 {
+	o.success.Add(1)
+	return o.failing.Swap(0)
+}
+
+// RecordError counts a failed publish and returns the number of consecutive
+// failed publishes, this one included.
+func (o *OutputCounters) RecordError(err error) uint64 {
+	o.errors.Add(1)
+	o.mu.Lock()
+	o.lastError, o.lastErrorAt = err.Error(), time.Now()
+	o.mu.Unlock()
+	return o.failing.Add(1)
+}
+
+// RecordRetry counts a publish retried by the retry policy.
+func (o *OutputCounters) RecordRetry() { o.retries.Add(1) }
+
+// RecordDropped counts n snapshots given up on.
+func (o *OutputCounters) RecordDropped(n int) { o.dropped.Add(uint64(n)) }
+
+// SetBuffered sets the number of snapshots waiting to be published again.
+func (o *OutputCounters) SetBuffered(n int) { o.buffered.Store(int64(n)) }
+
+// Snapshot returns the current counter values.
+func (o *OutputCounters) Snapshot() OutputSnapshot {
+	o.mu.Lock()
+	lastError, lastErrorAt := o.lastError, o.lastErrorAt
+	o.mu.Unlock()
+	return OutputSnapshot{
+		Index:             o.index,
+		Type:              o.typ,
+		OnError:           o.policy,
+		Published:         o.success.Load(),
+		Errors:            o.errors.Load(),
+		Retries:           o.retries.Load(),
+		Dropped:           o.dropped.Load(),
+		Buffered:          int(o.buffered.Load()),
+		ConsecutiveErrors: o.failing.Load(),
+		LastError:         lastError,
+		LastErrorAt:       lastErrorAt,
+	}
+}
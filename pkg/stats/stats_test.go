@@ -0,0 +1,80 @@
+package stats
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCountersSnapshot(t *testing.T) {
+	var c Counters
+	if s := c.Snapshot(); !s.LastRead.IsZero() || s.Reads != 0 || s.Outputs != nil {
+		t.Fatalf("zero value snapshot = %+v", s)
+	}
+	at := time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)
+	c.RecordRead(at.Add(-time.Second))
+	c.RecordRead(at)
+	c.RecordReadError()
+	c.RecordPublishError()
+	c.Output(2, "webhook", "retry")
+	c.Output(0, "mqtt", "drop")
+	s := c.Snapshot()
+	if s.Reads != 2 || s.ReadErrors != 1 || s.PublishErrors != 1 || !s.LastRead.Equal(at) {
+		t.Fatalf("snapshot = %+v", s)
+	}
+	if len(s.Outputs) != 2 || s.Outputs[0].Index != 0 || s.Outputs[1].Index != 2 || s.Outputs[1].Type != "webhook" || s.Outputs[1].OnError != "retry" {
+		t.Fatalf("outputs = %+v", s.Outputs)
+	}
+}
+
+func TestOutputReplacement(t *testing.T) {
+	var c Counters
+	o := c.Output(1, "webhook", "drop")
+	o.RecordSuccess()
+	if c.Output(1, "webhook", "drop") != o {
+		t.Fatal("same output got new counters")
+	}
+	// another type or policy at the index is another output
+	for _, tt := range []struct{ typ, policy string }{{"nats", "drop"}, {"nats", "retry"}} {
+		n := c.Output(1, tt.typ, tt.policy)
+		if n == o {
+			t.Fatalf("%s/%s: counters not replaced", tt.typ, tt.policy)
+		}
+		if s := n.Snapshot(); s.Published != 0 || s.Type != tt.typ || s.OnError != tt.policy {
+			t.Fatalf("%s/%s: replaced counters = %+v", tt.typ, tt.policy, s)
+		}
+		o = n
+	}
+	if s := c.Snapshot(); len(s.Outputs) != 1 || s.Outputs[0].Type != "nats" || s.Outputs[0].OnError != "retry" {
+		t.Fatalf("outputs = %+v", s.Outputs)
+	}
+}
+
+func TestOutputConsecutiveErrors(t *testing.T) {
+	o := (&Counters{}).Output(0, "webhook", "buffer")
+	if n := o.RecordSuccess(); n != 0 {
+		t.Fatalf("success without failures ended %d", n)
+	}
+	for want := uint64(1); want <= 3; want++ {
+		if n := o.RecordError(errors.New("status 503")); n != want {
+			t.Fatalf("consecutive errors = %d, want %d", n, want)
+		}
+	}
+	o.RecordRetry()
+	o.RecordDropped(2)
+	o.SetBuffered(4)
+	s := o.Snapshot()
+	if s.Errors != 3 || s.ConsecutiveErrors != 3 || s.Retries != 1 || s.Dropped != 2 || s.Buffered != 4 || s.LastError != "status 503" || s.LastErrorAt.IsZero() {
+		t.Fatalf("snapshot = %+v", s)
+	}
+	if n := o.RecordSuccess(); n != 3 {
+		t.Fatalf("success ended %d failures, want 3", n)
+	}
+	s = o.Snapshot()
+	if s.Published != 2 || s.ConsecutiveErrors != 0 || s.Errors != 3 || s.LastError != "status 503" {
+		t.Fatalf("snapshot after recovery = %+v", s)
+	}
+	if n := o.RecordError(errors.New("timeout")); n != 1 {
+		t.Fatalf("consecutive errors after recovery = %d, want 1", n)
+	}
+}
@@ -0,0 +1,45 @@
+package stream
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/output"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+// StreamOutput publishes the aggregated snapshots of its output entry to a hub.
+type StreamOutput struct {
+	hub *Hub
+}
+
+func init() {
+	output.Register("stream", output.Registration{
+		New: func(_ any, env output.Env) (output.Output, error) {
+			if env.Config == nil || env.Config.HTTP == nil && env.Config.GRPC == nil {
+				return nil, fmt.Errorf("stream output requires the http or grpc server (http.address, grpc.address)")
+			}
+			return NewStream(Default), nil
+		},
+	})
+}
+
+func NewStream(hub *Hub) output.Output { return &StreamOutput{hub: hub} }
+
+func (s *StreamOutput) Publish(readings []sensor.Reading) error {
+	s.hub.Publish(KindSnapshot, readings)
+	return nil
+}
+
+func (s *StreamOutput) Close() error { return nil }
+
+//go:embed index.html
+var page embed.FS
+
+// Page serves the HTML page plotting the stream.
+func Page() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, page, "index.html")
+	})
+}
@@ -0,0 +1,201 @@
+// Package stream pushes readings to browsers with Server-Sent Events. The
+// sensor reader publishes every raw read and the "stream" output publishes
+// aggregated snapshots; clients choose one of them and the channels to receive.
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+const (
+	// KindRaw events carry each sensor read, KindSnapshot the aggregated snapshots.
+	KindRaw      = "raw"
+	KindSnapshot = "snapshot"
+	// DefaultBuffer is the number of events queued per client before it is dropped.
+	DefaultBuffer = 64
+	// keepAlive is the interval of SSE comments keeping idle connections open.
+	keepAlive = 15 * time.Second
+)
+
+// event is the JSON data of an SSE "readings" event.
+type event struct {
+	Kind     string           `json:"kind"`
+	Readings []sensor.Reading `json:"readings"`
+}
+
+// Subscription receives the readings of one kind published to a hub.
+type Subscription struct {
+	hub      *Hub
+	kind     string
+	channels map[int]bool // nil: all channels
+	events   chan []sensor.Reading
+	dropped  chan struct{}
+}
+
+// Hub fans readings out to the connected clients. Clients that do not keep up
+// (their buffer is full) are disconnected instead of slowing down publishers.
+type Hub struct {
+	buffer  int
+	mu      sync.Mutex
+	clients map[*Subscription]struct{}
+}
+
+// Default is the hub fed by the sensor reader and the stream output.
+var Default = NewHub(DefaultBuffer)
+
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Hub{buffer: buffer, clients: make(map[*Subscription]struct{})}
+}
+
+// Publish sends readings of the given kind to the interested clients.
+func (h *Hub) Publish(kind string, readings []sensor.Reading) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		if c.kind != kind {
+			continue
+		}
+		filtered := c.filter(readings)
+		if len(filtered) == 0 {
+			continue
+		}
+		select {
+		case c.events <- filtered:
+		default:
+			// slow client: drop it rather than block the publisher
+			delete(h.clients, c)
+			close(c.dropped)
+		}
+	}
+}
+
+// Clients returns the number of connected clients.
+func (h *Hub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Subscribe registers a consumer of the readings of kind (KindRaw or
+// KindSnapshot) restricted to channels (nil: all channels). The subscription
+// is dropped when its buffer is full; call Close when done.
+func (h *Hub) Subscribe(kind string, channels map[int]bool) *Subscription {
+	s := &Subscription{hub: h, kind: kind, channels: channels, events: make(chan []sensor.Reading, h.buffer), dropped: make(chan struct{})}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[s] = struct{}{}
+	return s
+}
+
+// Readings delivers the published readings.
+func (s *Subscription) Readings() <-chan []sensor.Reading { return s.events }
+
+// Dropped is closed when the subscription is dropped or closed.
+func (s *Subscription) Dropped() <-chan struct{} { return s.dropped }
+
+// Close unsubscribes.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.clients[s]; ok {
+		delete(s.hub.clients, s)
+		close(s.dropped)
+	}
+}
+
+func (s *Subscription) filter(readings []sensor.Reading) []sensor.Reading {
+	if s.channels == nil {
+		return readings
+	}
+	out := make([]sensor.Reading, 0, len(readings))
+	for _, r := range readings {
+		if s.channels[r.Channel] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// ServeHTTP streams events to the client. Query parameters: mode ("raw", the
+// default, or "snapshot") and channels (comma-separated channel indexes).
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	kind, channels, err := parseQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	c := h.Subscribe(kind, channels)
+	defer c.Close()
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case readings := <-c.events:
+			b, err := json.Marshal(event{Kind: kind, Readings: readings})
+			if err != nil {
+				log.Printf("stream: encode: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: readings\ndata: %s\n\n", b); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-c.dropped:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// parseQuery returns the kind and channels requested in the query string.
+func parseQuery(r *http.Request) (string, map[int]bool, error) {
+	q := r.URL.Query()
+	kind := KindRaw
+	switch mode := q.Get("mode"); mode {
+	case "", KindRaw:
+	case KindSnapshot:
+		kind = KindSnapshot
+	default:
+		return "", nil, fmt.Errorf("invalid mode %q: use %s or %s", mode, KindRaw, KindSnapshot)
+	}
+	var channels map[int]bool
+	if s := q.Get("channels"); s != "" {
+		channels = make(map[int]bool)
+		for _, p := range strings.Split(s, ",") {
+			ch, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid channel %q", p)
+			}
+			channels[ch] = true
+		}
+	}
+	return kind, channels, nil
+}
@@ -0,0 +1,85 @@
+package stream
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+)
+
+// waitClients waits until the hub has n clients.
+func waitClients(t *testing.T, h *Hub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for h.Clients() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d clients, want %d", h.Clients(), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStreamFiltersChannelsAndMode(t *testing.T) {
+	h := NewHub(4)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "?mode=snapshot&channels=1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %q", ct)
+	}
+	waitClients(t, h, 1)
+
+	ts := time.Date(2025, 9, 19, 14, 41, 54, 0, time.UTC)
+	h.Publish(KindRaw, []sensor.Reading{{Channel: 1, Value: 9, Timestamp: ts}})
+	h.Publish(KindSnapshot, []sensor.Reading{{Channel: 0, Value: 1, Timestamp: ts}, {Channel: 1, Raw: 2, Value: 0.5, Timestamp: ts}})
+
+	sc := bufio.NewScanner(resp.Body)
+	var lines []string
+	for sc.Scan() && len(lines) < 2 {
+		if sc.Text() != "" {
+			lines = append(lines, sc.Text())
+		}
+	}
+	want := []string{
+		"event: readings",
+		`data: {"kind":"snapshot","readings":[{"channel":1,"raw":2,"value":0.5,"timestamp":"2025-09-19T14:41:54Z"}]}`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSlowClientDropped(t *testing.T) {
+	h := NewHub(1)
+	c := h.Subscribe(KindRaw, nil)
+	h.Publish(KindRaw, []sensor.Reading{{Channel: 0}})
+	h.Publish(KindRaw, []sensor.Reading{{Channel: 0}})
+	select {
+	case <-c.Dropped():
+	default:
+		t.Fatalf("slow client not dropped")
+	}
+	if h.Clients() != 0 {
+		t.Fatalf("dropped client still subscribed")
+	}
+	c.Close() // must not close dropped twice
+}
+
+func TestInvalidQuery(t *testing.T) {
+	h := NewHub(1)
+	for _, q := range []string{"?mode=avg", "?channels=a"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", q, rec.Code)
+		}
+	}
+}
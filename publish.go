@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericogr/ads1115-to-mqtt/pkg/config"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/sensor"
+	"github.com/ericogr/ads1115-to-mqtt/pkg/stats"
+)
+
+const (
+	defaultRetryAttempts = 3
+	defaultRetryBackoff  = 500 * time.Millisecond
+	maxRetryBackoff      = 30 * time.Second
+	defaultErrorBuffer   = 100
+	// failureLogInterval limits the failure logs of an output failing more
+	// than quietAfterFailures times in a row.
+	failureLogInterval = time.Minute
+	quietAfterFailures = 10
+)
+
+// errorPolicy is the resolved publish error policy of an output.
+type errorPolicy struct {
+	policy   string
+	attempts int
+	backoff  time.Duration
+	buffer   int
+}
+
+func newErrorPolicy(c *config.ErrorPolicyConfig) errorPolicy {
+	p := errorPolicy{policy: config.OnErrorDrop, attempts: defaultRetryAttempts, backoff: defaultRetryBackoff, buffer: defaultErrorBuffer}
+	if c == nil {
+		return p
+	}
+	if c.Policy != "" {
+		p.policy = c.Policy
+	}
+	if c.Attempts > 0 {
+		p.attempts = c.Attempts
+	}
+	if c.BackoffMs > 0 {
+		p.backoff = time.Duration(c.BackoffMs) * time.Millisecond
+	}
+	if c.Buffer > 0 {
+		p.buffer = c.Buffer
+	}
+	return p
+}
+
+// publish sends a snapshot according to the error policy of the output. It
+// only blocks the worker of this output, for at most the retry backoffs.
+func (e *outputEntry) publish(snapshot []sensor.Reading, done <-chan struct{}) {
+	if e.policy.policy == config.OnErrorBuffer {
+		e.publishBuffered(snapshot)
+		return
+	}
+	if len(snapshot) == 0 {
+		return
+	}
+	err := e.tryPublish(snapshot)
+	if err == nil {
+		return
+	}
+	switch e.policy.policy {
+	case config.OnErrorFatal:
+		log.Fatalf("output %s publish error: %v", e.name(), err)
+	case config.OnErrorRetry:
+		backoff := e.policy.backoff
+		for attempt := 0; attempt < e.policy.attempts && err != nil; attempt++ {
+			select {
+			case <-time.After(backoff):
+			case <-done:
+				return
+			}
+			e.counters.RecordRetry()
+			err = e.tryPublish(snapshot)
+			backoff = min(2*backoff, maxRetryBackoff)
+		}
+		if err == nil {
+			return
+		}
+	}
+	e.counters.RecordDropped(1)
+	e.logFailure(err, "snapshot dropped")
+}
+
+// publishBuffered queues the snapshot behind the ones that failed before and
+// publishes the queue in order, stopping at the first failure.
+func (e *outputEntry) publishBuffered(snapshot []sensor.Reading) {
+	if len(snapshot) > 0 {
+		e.pending = append(e.pending, snapshot)
+		if over := len(e.pending) - e.policy.buffer; over > 0 {
+			e.counters.RecordDropped(over)
+			e.pending = append(e.pending[:0], e.pending[over:]...)
+		}
+	}
+	defer func() { e.counters.SetBuffered(len(e.pending)) }()
+	for len(e.pending) > 0 {
+		if err := e.tryPublish(e.pending[0]); err != nil {
+			e.logFailure(err, fmt.Sprintf("%d snapshots buffered", len(e.pending)))
+			return
+		}
+		e.pending[0] = nil
+		e.pending = e.pending[1:]
+	}
+	e.pending = nil
+}
+
+// tryPublish publishes once and records the outcome; a panicking output is
+// reported as a failed publish.
+func (e *outputEntry) tryPublish(snapshot []sensor.Reading) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		if err != nil {
+			stats.Default.RecordPublishError()
+			e.counters.RecordError(err)
+			return
+		}
+		if failed := e.counters.RecordSuccess(); failed > 0 {
+			s := e.counters.Snapshot()
+			log.Printf("output %s: recovered after %d failed publishes (published=%d errors=%d dropped=%d)", e.name(), failed, s.Published, s.Errors, s.Dropped)
+		}
+	}()
+	return e.Out.Publish(snapshot)
+}
+
+// logFailure logs a failed publish with the output counters: every failure
+// at first, then once per failureLogInterval while the output keeps failing.
+func (e *outputEntry) logFailure(err error, outcome string) {
+	s := e.counters.Snapshot()
+	if s.ConsecutiveErrors > quietAfterFailures && time.Since(e.lastFailureLog) < failureLogInterval {
+		return
+	}
+	e.lastFailureLog = time.Now()
+	log.Printf("warning: output %s: publish failed, %s: %v (published=%d errors=%d retries=%d dropped=%d)", e.name(), outcome, err, s.Published, s.Errors, s.Retries, s.Dropped)
+}
+
+// name identifies the output in logs, e.g. "1 (webhook)".
+func (e *outputEntry) name() string {
+	return fmt.Sprintf("%d (%s)", e.Index, e.typ)
+}